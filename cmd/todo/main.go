@@ -1,21 +1,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/hwkd/todo-cli/internal/args"
+	"github.com/hwkd/todo-cli/internal/config"
+	"github.com/hwkd/todo-cli/internal/ical"
+	"github.com/hwkd/todo-cli/internal/markdown"
+	"github.com/hwkd/todo-cli/internal/output"
+	"github.com/hwkd/todo-cli/internal/query"
+	"github.com/hwkd/todo-cli/internal/search"
 	"github.com/hwkd/todo-cli/internal/todo"
 )
 
 func main() {
 	if err := run(); err != nil {
-		if err, ok := err.(args.ArgError); ok {
-			fmt.Println(err)
-			displayUsage(err.Action)
-		} else {
-			fmt.Println(err)
+		fmt.Println(err)
+		if argErr, ok := err.(args.ArgError); ok {
+			displayUsage(argErr.Action)
 		}
 		return
 	}
@@ -27,17 +36,55 @@ func run() error {
 		return err
 	}
 
-	todoList, err := todo.NewTodoList(todo.NewTodoListCsvStore("todo.csv"))
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	todoFile, err := cfg.TodoFile(result.ParseGlobalOptions().File)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(todoFile), 0755); err != nil {
+		return err
+	}
+
+	// Hold the lock from loading until the changes are flushed, so concurrent invocations don't drop each
+	// other's edits.
+	lock, err := todo.LockFile(todo.LockPath(todoFile))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// Repairing loads the file as is, since a list with duplicate IDs can't be loaded.
+	if result.Action == args.ActionRepair {
+		return handleRepairAction(todo.NewStore(todoFile))
+	}
+
+	todoList, err := todo.NewTodoList(todo.NewStore(todoFile))
+	if errors.Is(err, todo.ErrDuplicateID) {
+		return fmt.Errorf("%w. Run `todo repair` to give them new IDs", err)
+	}
 	if err != nil {
 		return err
 	}
+	if err := todoList.Recovered(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+	todoList.UseJournal(todo.NewJournal(todo.JournalPath(todoFile)))
+	todoList.UseArchive(todo.NewStore(todo.ArchivePath(todoFile)))
+	if err := tidy(todoList, cfg); err != nil {
+		return err
+	}
 
 	err = nil
 	switch result.Action {
 	case args.ActionHelp:
 		handleHelpAction()
 	case args.ActionList:
-		handleListAction(*todoList)
+		err = handleListAction(*todoList, cfg, result.ParseListActionValues())
+	case args.ActionSearch:
+		handleSearchAction(*todoList, result.ParseSearchActionValues())
 	case args.ActionAdd:
 		err = handleAddAction(todoList, result.ParseAddActionValues())
 	case args.ActionUpdate:
@@ -48,10 +95,45 @@ func run() error {
 		err = handleMarkCompleteAction(todoList, result.ParseMarkCompleteActionValues())
 	case args.ActionMarkIncomplete:
 		err = handleMarkInompleteAction(todoList, result.ParseMarkIncompleteActionValues())
+	case args.ActionUndo:
+		err = handleUndoAction(todoList, result.ParseUndoActionValues())
+	case args.ActionRedo:
+		err = handleRedoAction(todoList, result.ParseRedoActionValues())
+	case args.ActionTrash:
+		err = handleTrashAction(todoList, result.ParseTrashActionValues())
+	case args.ActionArchive:
+		err = handleArchiveAction(todoList, result.ParseArchiveActionValues())
+	case args.ActionDependency:
+		err = handleDependencyAction(todoList, result.ParseDependencyActionValues())
+	case args.ActionExport:
+		err = handleExportAction(todoList, result.ParseExportActionValues())
+	case args.ActionImport:
+		err = handleImportAction(todoList, result.ParseImportActionValues())
 	}
 	return err
 }
 
+// tidy purges the trash and archives done todos as set in the config file. The changes are not journaled,
+// since tidy runs before every command, including undo and redo.
+func tidy(todoList *todo.TodoList, cfg *config.Config) error {
+	tidied := false
+	if retention, ok := cfg.TrashRetention(); ok {
+		purged := todoList.Purge(time.Now().Add(-retention))
+		tidied = tidied || len(purged) > 0
+	}
+	if after, ok := cfg.ArchiveAfter(); ok {
+		archived, err := todoList.Archive(time.Now().Add(-after))
+		if err != nil {
+			return err
+		}
+		tidied = tidied || len(archived) > 0
+	}
+	if !tidied {
+		return nil
+	}
+	return todoList.FlushUnrecorded()
+}
+
 var actions = []struct {
 	action      string
 	flag        string
@@ -59,12 +141,21 @@ var actions = []struct {
 	description string
 }{
 	{args.ActionHelp, "-h", "", ""},
-	{args.ActionList, "-l", "", ""},
-	{args.ActionAdd, "-a", "<title> [description]", "Add a todo item"},
-	{args.ActionUpdate, "-u", "<id> [-t title] [-d description]", "Update a todo item"},
-	{args.ActionDelete, "-d", "<id>...", "Delete todo items by id"},
-	{args.ActionMarkComplete, "-c", "<id>...", "Mark complete by id"},
-	{args.ActionMarkIncomplete, "-r", "<id>...", "Mark incomplete by id"},
+	{args.ActionList, "-l", "[query...] [-p priority] [--completed today|week] [--sort keys] [--limit n] [--format f] [--template t] [--archived] [--ready]", "List todo items matching a query, most important first, or only those ready to start"},
+	{args.ActionSearch, "-s", "<term>...", "Search titles and descriptions"},
+	{args.ActionAdd, "-a", "<title> [description] [-p priority] [--due date] [--repeat rule] [--parent id]", "Add a todo item, or a subtask of the parent, with +project and @tag tokens in the title"},
+	{args.ActionUpdate, "-u", "<id> [-t title] [-d description] [-p priority] [--due date] [--repeat rule] [[-]+project] [[-]@tag...]", "Update a todo item"},
+	{args.ActionDelete, "-d", "<id>...", "Move todo items and their subtasks to the trash by number or id"},
+	{args.ActionMarkComplete, "-c", "<id>...", "Mark complete with their subtasks by number or id"},
+	{args.ActionMarkIncomplete, "-r", "<id>...", "Mark incomplete by number or id"},
+	{args.ActionUndo, "undo", "[n]", "Undo the last n changes"},
+	{args.ActionRedo, "redo", "[n]", "Redo the last n undone changes"},
+	{args.ActionTrash, "trash", "[--purge | --restore <id>...]", "List the trash, empty it, or restore todo items from it"},
+	{args.ActionArchive, "archive", "[--restore <id>...]", "Move done todo items to the archive, or restore them from it"},
+	{args.ActionDependency, "dep", "add|rm <id> <blocker-id>...", "Make a todo item wait for others to be done, or stop waiting"},
+	{args.ActionExport, "export", "--ical | --markdown", "Write todo items to standard output as iCalendar VTODOs or a Markdown checklist"},
+	{args.ActionImport, "import", "<file.ics | file.md>", "Add todo items from a calendar, updating those that changed since, or from a checklist, skipping titles already listed"},
+	{args.ActionRepair, "repair", "", "Give new IDs to todo items sharing an ID"},
 }
 
 func displayUsage(action string) {
@@ -84,72 +175,472 @@ func handleHelpAction() {
 	for _, action := range actions {
 		fmt.Fprintf(writer, "  todo %s\t%s\t%s\n", action.flag, action.params, action.description)
 	}
+	fmt.Fprint(writer, "\nOptions:\n")
+	fmt.Fprintf(writer, "  --file <path>\tUse the todo file at path, also set by %s\n", config.EnvFile)
+	fmt.Fprint(writer, "\nIDs:\n")
+	fmt.Fprint(writer, "  the number of a todo item, its id, or the shortest prefix of the id shown by `todo -l`\n")
+	fmt.Fprint(writer, "\nFormats:\n")
+	fmt.Fprintf(writer, "  %s\n", strings.Join(output.Formats, ", "))
+	fmt.Fprint(writer, "\nTemplates:\n")
+	fmt.Fprint(writer, "  text/template run for each item, such as '{{.ID | short}} {{.Title | truncate 30}} {{.DueAt | relative}}',\n")
+	fmt.Fprint(writer, "  or the name of a template in the `templates` setting of the config file; tabs align columns\n")
+	fmt.Fprint(writer, "  functions: short, title, indent, progress, blocked, due, date, relative, truncate, join, color, dueColor, reset\n")
+	fmt.Fprint(writer, "\nQueries:\n")
+	fmt.Fprint(writer, "  field:value, field~text, field<value, field<=value, field>value, field>=value, +project, @tag or text\n")
+	fmt.Fprint(writer, "  fields: id, title, desc, done, priority, project, tag, due, created, updated, completed\n")
+	fmt.Fprint(writer, "  terms must all match unless joined by `or`; -term or `not term` negates a term\n")
+	fmt.Fprint(writer, "\nDates:\n")
+	fmt.Fprint(writer, "  today, tomorrow, fri, +3d, +2w, +1m, 2026-11-01, optionally followed by a time such as 17:00\n")
+	fmt.Fprint(writer, "\nRepeats:\n")
+	fmt.Fprint(writer, "  daily, weekly, weekly:mon,thu, monthly, monthly:15, +3d (3 days after completion), an RRULE, or none\n")
 	writer.Flush()
 }
 
-func handleListAction(todoList todo.TodoList) {
-	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(writer, "ID\tTitle\tDescription\tDone\tCreated At")
-	fmt.Fprintln(writer, "--\t-----\t-----------\t----\t----------")
-	todos := todoList.List()
-	for _, todoItem := range todos {
-		fmt.Fprintf(
-			writer,
-			"%s\t%s\t%s\t%t\t%s\n",
-			todoItem.ID,
-			todoItem.Title,
-			todoItem.Description,
-			todoItem.IsDone,
-			todoItem.CreatedAt.Format("2006-01-02 03:04:05 PM"),
-		)
+func handleListAction(todoList todo.TodoList, cfg *config.Config, values args.ParsedListActionValues) error {
+	filter, err := parseFilter(values, time.Now())
+	if err != nil {
+		return args.NewArgError(args.ActionList, err)
+	}
+	if values.Archived {
+		archive, err := todoList.Archived()
+		if err != nil {
+			return err
+		}
+		todoList = *archive
+	}
+	todos := query.Filter(todoList.List(), filter)
+	blocked := todoList.Blocked()
+	if values.Ready {
+		ready := []todo.TodoItem{}
+		for _, todoItem := range todos {
+			if !todoItem.IsDone && !blocked[todoItem.ID] {
+				ready = append(ready, todoItem)
+			}
+		}
+		todos = ready
+	}
+
+	if values.Sort != "" {
+		keys, err := query.ParseSort(values.Sort)
+		if err != nil {
+			return args.NewArgError(args.ActionList, err)
+		}
+		query.Sort(todos, keys)
+	} else {
+		todo.SortByPriority(todos)
+	}
+	todos, depths := todo.Tree(todos)
+
+	if values.Limit > 0 && len(todos) > values.Limit {
+		todos = todos[:values.Limit]
+	}
+
+	options := output.Options{
+		Color:    output.UseColor(os.Stdout),
+		Now:      time.Now(),
+		ShortIDs: todoList.ShortIDs(),
+		Depths:   depths,
+		Progress: todoList.Progress(),
+		Blocked:  blocked,
+	}
+	var renderer output.Renderer
+	if values.Template != "" {
+		if values.Format != "" && values.Format != output.FormatTable {
+			return args.NewArgError(args.ActionList, fmt.Errorf("%w: --template cannot be used with --format %s", args.ErrInvalidArg, values.Format))
+		}
+		renderer, err = output.NewTemplateRenderer(cfg.Template(values.Template), options)
+	} else {
+		renderer, err = output.NewRenderer(values.Format, options)
+	}
+	if err != nil {
+		return args.NewArgError(args.ActionList, err)
+	}
+	return renderer.Render(os.Stdout, todos)
+}
+
+// parseFilter parses the query of the list action together with its filters, which each must match as well.
+func parseFilter(values args.ParsedListActionValues, now time.Time) (query.Node, error) {
+	node, err := query.Parse(values.Query, now)
+	if err != nil || len(values.Filters) == 0 {
+		return node, err
+	}
+	filter := query.And{}
+	if node != nil {
+		filter.Nodes = append(filter.Nodes, node)
+	}
+	for _, term := range values.Filters {
+		node, err := query.Parse([]string{term}, now)
+		if err != nil {
+			return nil, err
+		}
+		filter.Nodes = append(filter.Nodes, node)
+	}
+	return filter, nil
+}
+
+func handleSearchAction(todoList todo.TodoList, values args.ParsedSearchActionValues) {
+	terms := strings.Join(values.Terms, " ")
+	results := search.Search(todoList.List(), terms)
+	if len(results) == 0 {
+		fmt.Println("No todo items found")
+		return
+	}
+
+	before, after := "", ""
+	if output.UseColor(os.Stdout) {
+		before, after = output.ColorHighlight, output.ColorReset
+	}
+	shortIDs := todoList.ShortIDs()
+	width := 0
+	for _, result := range results {
+		width = max(width, len(fmt.Sprintf("%d  %s", result.Todo.Num, shortIDs[result.Todo.ID])))
+	}
+	for _, result := range results {
+		handle := fmt.Sprintf("%d  %s", result.Todo.Num, shortIDs[result.Todo.ID])
+		fmt.Printf("%-*s  %s\n", width, handle, search.Highlight(output.FormatTitle(result.Todo), terms, before, after))
+		if result.Todo.Description != "" {
+			fmt.Printf("%-*s  %s\n", width, "", search.Highlight(result.Todo.Description, terms, before, after))
+		}
 	}
-	writer.Flush()
 }
 
 func handleAddAction(todoList *todo.TodoList, values args.ParsedAddActionValues) error {
-	todoItem := todo.NewTodoItem(values.Title, values.Description)
+	todoItem, err := todo.NewTodoItem(values.Title, values.Description)
+	if err != nil {
+		return err
+	}
+	todoItem.Priority = values.Priority
+	todoItem.DueAt = values.DueAt
+	todoItem.Recurrence = values.Recurrence
+	todoItem.Project = values.Project
+	todoItem.Tags = values.Tags
+	if values.Parent != "" {
+		parent, err := todoList.Get(values.Parent)
+		if err != nil {
+			return err
+		}
+		todoItem.ParentID = parent.ID
+	}
 	todoList.Add(*todoItem)
 	return todoList.Flush()
 }
 
 func handleUpdateAction(todoList *todo.TodoList, values args.ParsedUpdateActionValues) error {
-	todo := todoList.Get(values.ID)
-	if todo == nil {
-		return fmt.Errorf("Todo with %s not found\n", values.ID)
+	todo, err := todoList.Get(values.ID)
+	if err != nil {
+		return err
+	}
+	if values.Title != nil {
+		todo.Title = *values.Title
+	}
+	if values.Description != nil {
+		todo.Description = *values.Description
+	}
+	if values.Priority != nil {
+		todo.Priority = *values.Priority
+	}
+	if values.DueAt != nil {
+		todo.DueAt = *values.DueAt
+	}
+	if values.Recurrence != nil {
+		todo.Recurrence = *values.Recurrence
+	}
+	if values.Project != nil {
+		todo.Project = *values.Project
+	}
+	for _, tag := range values.AddTags {
+		todo.AddTag(tag)
+	}
+	for _, tag := range values.RemoveTags {
+		todo.RemoveTag(tag)
 	}
-	todo.Title = values.Title
-	todo.Description = values.Description
 	todoList.Update(*todo)
 	return todoList.Flush()
 }
 
 func handleDeleteAction(todoList *todo.TodoList, result args.ParsedIdValues) error {
 	for _, id := range result.IDs {
-		todoList.Delete(id)
+		if err := todoList.Delete(id); err != nil {
+			return err
+		}
 	}
 	return todoList.Flush()
 }
 
 func handleMarkCompleteAction(todoList *todo.TodoList, result args.ParsedIdValues) error {
 	for _, id := range result.IDs {
-		todo := todoList.Get(id)
-		if todo == nil {
-			return fmt.Errorf("Todo with %s not found\n", id)
+		todoItem, err := todoList.Get(id)
+		if err != nil {
+			return err
+		}
+		if blockers := todoList.Blockers(*todoItem); len(blockers) > 0 && !todoItem.IsDone {
+			nums := []string{}
+			for _, blocker := range blockers {
+				nums = append(nums, strconv.Itoa(blocker.Num))
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %d is blocked by %s\n", todoItem.Num, strings.Join(nums, ", "))
+		}
+		num := todoItem.Num
+		subtasks, next, err := todoList.Complete(todoItem.ID)
+		if err != nil {
+			return err
+		}
+		if len(subtasks) > 0 {
+			fmt.Printf("Completed %d subtasks of %d\n", len(subtasks), num)
+		}
+		if next != nil {
+			fmt.Printf("Repeats as %d, due %s\n", next.Num, output.FormatDue(*next))
 		}
-		todo.Done()
-		todoList.Update(*todo)
 	}
 	return todoList.Flush()
 }
 
 func handleMarkInompleteAction(todoList *todo.TodoList, result args.ParsedIdValues) error {
 	for _, id := range result.IDs {
-		todo := todoList.Get(id)
-		if todo == nil {
-			return fmt.Errorf("Todo with %s not found\n", id)
+		todo, err := todoList.Get(id)
+		if err != nil {
+			return err
 		}
 		todo.Undone()
 		todoList.Update(*todo)
 	}
 	return todoList.Flush()
 }
+
+func handleRepairAction(store todo.Store) error {
+	repaired, err := todo.RepairIDs(store)
+	if err != nil {
+		return err
+	}
+	if len(repaired) == 0 {
+		fmt.Println("No duplicate IDs found")
+		return nil
+	}
+	for _, todoItem := range repaired {
+		fmt.Printf("%s  %s\n", todoItem.ID, todoItem.Title)
+	}
+	fmt.Printf("Gave new IDs to %d todo items\n", len(repaired))
+	return nil
+}
+
+func handleUndoAction(todoList *todo.TodoList, values args.ParsedCountValues) error {
+	entries, err := todoList.Undo(values.Count)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to undo")
+	}
+	for _, entry := range entries {
+		fmt.Printf("Undid %s\n", entry)
+	}
+	return nil
+}
+
+func handleRedoAction(todoList *todo.TodoList, values args.ParsedCountValues) error {
+	entries, err := todoList.Redo(values.Count)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to redo")
+	}
+	for _, entry := range entries {
+		fmt.Printf("Redid %s\n", entry)
+	}
+	return nil
+}
+
+func handleTrashAction(todoList *todo.TodoList, values args.ParsedTrashActionValues) error {
+	if values.Purge {
+		purged := todoList.Purge(time.Now())
+		fmt.Printf("Purged %d todo items\n", len(purged))
+		return todoList.Flush()
+	}
+	if len(values.Restore) > 0 {
+		for _, id := range values.Restore {
+			todoItem, err := todoList.Restore(id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Restored %d  %s\n", todoItem.Num, todoItem.Title)
+		}
+		return todoList.Flush()
+	}
+
+	trash := todoList.Trash()
+	if len(trash) == 0 {
+		fmt.Println("The trash is empty")
+		return nil
+	}
+	shortIDs := todoList.ShortIDs()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(writer, "#\tID\tTitle\tDeleted\n")
+	for _, todoItem := range trash {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", todoItem.Num, shortIDs[todoItem.ID], output.FormatTitle(todoItem), output.FormatRelative(todoItem.DeletedAt, time.Now()))
+	}
+	return writer.Flush()
+}
+
+func handleArchiveAction(todoList *todo.TodoList, values args.ParsedArchiveActionValues) error {
+	for _, id := range values.Restore {
+		todoItem, err := todoList.Unarchive(id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored %d  %s\n", todoItem.Num, todoItem.Title)
+	}
+	if len(values.Restore) > 0 {
+		return todoList.Flush()
+	}
+
+	archived, err := todoList.Archive(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Archived %d todo items\n", len(archived))
+	return todoList.Flush()
+}
+
+func handleDependencyAction(todoList *todo.TodoList, values args.ParsedDependencyActionValues) error {
+	for _, blockerID := range values.Blockers {
+		var err error
+		if values.Remove {
+			err = todoList.RemoveDependency(values.ID, blockerID)
+		} else {
+			err = todoList.AddDependency(values.ID, blockerID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return todoList.Flush()
+}
+
+func handleExportAction(todoList *todo.TodoList, values args.ParsedExportActionValues) error {
+	switch values.Format {
+	case "ical":
+		return ical.Encode(os.Stdout, todoList.List(), time.Now())
+	case "markdown":
+		return markdown.Encode(os.Stdout, todoList.List())
+	}
+	return nil
+}
+
+func handleImportAction(todoList *todo.TodoList, values args.ParsedImportActionValues) error {
+	var importFile func(*todo.TodoList, *os.File) error
+	switch strings.ToLower(filepath.Ext(values.Path)) {
+	case ".ics":
+		importFile = importCalendar
+	case ".md", ".markdown":
+		importFile = importChecklist
+	default:
+		return args.NewArgError(args.ActionImport, fmt.Errorf("%w: Expected a .ics or .md file, got %s", args.ErrInvalidArg, values.Path))
+	}
+
+	file, err := os.Open(values.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := importFile(todoList, file); err != nil {
+		return err
+	}
+	return todoList.Flush()
+}
+
+// importCalendar adds the VTODOs of an iCalendar file, and updates the todos with the same ID that they changed
+// since.
+func importCalendar(todoList *todo.TodoList, file *os.File) error {
+	imported, warnings, err := ical.Decode(file, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	// Todos in the trash or the archive keep their IDs, so adding them again would duplicate the IDs.
+	hidden := map[string]bool{}
+	for _, todoItem := range todoList.Trash() {
+		hidden[todoItem.ID] = true
+	}
+	archive, err := todoList.Archived()
+	if err != nil && !errors.Is(err, todo.ErrNoArchive) {
+		return err
+	}
+	if archive != nil {
+		for _, todoItem := range archive.List() {
+			hidden[todoItem.ID] = true
+		}
+	}
+
+	todos := []todo.TodoItem{}
+	skipped := 0
+	for _, todoItem := range imported {
+		if hidden[todoItem.ID] {
+			skipped++
+			continue
+		}
+		existing, err := todoList.Get(todoItem.ID)
+		if err == nil && existing.ID == todoItem.ID && !todoItem.UpdatedAt.After(existing.UpdatedAt) {
+			continue
+		}
+		todos = append(todos, todoItem)
+	}
+	dropped := todoList.DropCycles(todos)
+
+	added, updated := 0, 0
+	for _, todoItem := range todos {
+		existing, err := todoList.Get(todoItem.ID)
+		if err != nil || existing.ID != todoItem.ID {
+			todoList.Add(todoItem)
+			added++
+			continue
+		}
+		merged := *existing
+		ical.Merge(&merged, todoItem)
+		todoList.Update(merged)
+		updated++
+	}
+	fmt.Printf("Imported %d todo items, updated %d\n", added, updated)
+	if skipped > 0 {
+		fmt.Printf("Skipped %d todo items in the trash or the archive\n", skipped)
+	}
+	if dropped > 0 {
+		fmt.Printf("Dropped %d parents or dependencies that would make a cycle\n", dropped)
+	}
+	return nil
+}
+
+// importChecklist adds the items of a Markdown checklist, skipping those with the title of a listed todo. Subtasks
+// of a skipped item become subtasks of the listed todo.
+func importChecklist(todoList *todo.TodoList, file *os.File) error {
+	imported, err := markdown.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	titles := map[string]string{}
+	for _, todoItem := range todoList.List() {
+		titles[strings.ToLower(todoItem.Title)] = todoItem.ID
+	}
+	// replaced maps the IDs of skipped items to the IDs of the listed todos with their titles.
+	replaced := map[string]string{}
+	added, skipped := 0, 0
+	for _, todoItem := range imported {
+		if id, ok := replaced[todoItem.ParentID]; ok {
+			todoItem.ParentID = id
+		}
+		if id, ok := titles[strings.ToLower(todoItem.Title)]; ok {
+			replaced[todoItem.ID] = id
+			skipped++
+			continue
+		}
+		titles[strings.ToLower(todoItem.Title)] = todoItem.ID
+		todoList.Add(todoItem)
+		added++
+	}
+	fmt.Printf("Imported %d todo items, skipped %d already listed\n", added, skipped)
+	return nil
+}
@@ -3,44 +3,93 @@ package args
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
 )
 
 /*
 Usage:
-  List todolist:
+  Global options, given before the action:
+    todo [--file path] ...
+
+  List todolist, filtered by a query such as `done:false tag:work due<fri title~deploy`:
     todo
+    todo -l [query...] [-p priority] [--sort keys] [--limit n] [--format json|ndjson|csv|tsv|table] [--template template] [--archived]
+    todo -l --completed today|week
+    todo -l --ready
+
+  Add todo, where the title can contain +project and @tag tokens:
+    todo -a <title> [description] [-p priority] [--due date] [--repeat rule] [--parent id]
 
-  Add todo:
-    todo -a <title> [description]
+  Update field, where +project sets the project, -+project clears it, @tag adds a tag and -@tag removes it:
+    todo -u <id> [-t title] [-d description] [-p priority] [--due date] [--repeat rule] [+project] [-+project] [@tag...] [-@tag...]
 
-  Update field:
-    todo -u <id> [-t title] [-d description]
+  Search titles and descriptions:
+    todo -s <term> [term2 term3 ...]
 
-  Delete:
+  Delete, moving todos to the trash:
     todo -d <id> [id2 id3 ...]
 
+  List the trash, empty it, or restore todos from it:
+    todo trash
+    todo trash --purge
+    todo trash --restore <id> [id2 id3 ...]
+
+  Move done todos to the archive, or restore todos from it:
+    todo archive
+    todo archive --restore <id> [id2 id3 ...]
+
   Mark todo as complete:
     todo -c <id> [id2 id3 ...]
 
   Mark todo as incomplete:
     todo -r <id> [id2 id3 ...]
+
+  Make a todo depend on others, so it is blocked until they are done, or not anymore:
+    todo dep add <id> <blocker-id> [blocker-id2 ...]
+    todo dep rm <id> <blocker-id> [blocker-id2 ...]
+
+  Export todos to standard output, or import todos from a file, updating those with the same ID:
+    todo export --ical|--markdown
+    todo import <file.ics|file.md>
+
+  Give new IDs to todos sharing an ID:
+    todo repair
+
+  Undo or redo the last n changes, 1 by default:
+    todo undo [n]
+    todo redo [n]
 */
 
 const (
 	ActionUndefined      = "undefined"
 	ActionHelp           = "help"
 	ActionList           = "list"
+	ActionSearch         = "search"
 	ActionAdd            = "add"
 	ActionUpdate         = "update"
 	ActionDelete         = "delete"
 	ActionMarkComplete   = "mark_complete"
 	ActionMarkIncomplete = "mark_incomplete"
+	ActionRepair         = "repair"
+	ActionUndo           = "undo"
+	ActionRedo           = "redo"
+	ActionTrash          = "trash"
+	ActionArchive        = "archive"
+	ActionDependency     = "dependency"
+	ActionExport         = "export"
+	ActionImport         = "import"
 )
 
 var (
 	ErrUnsupportedAction = errors.New("Action not supported")
 	ErrWrongFlag         = errors.New("Parsing wrong flag")
 	ErrMissingArg        = errors.New("Missing argument")
+	ErrUnknownArg        = errors.New("Unknown argument")
+	ErrInvalidArg        = errors.New("Invalid argument")
 )
 
 type ArgError struct {
@@ -52,6 +101,17 @@ func (e ArgError) Error() string {
 	return fmt.Sprintf("Argument error for '%s'. %s.", e.Action, e.error)
 }
 
+// NewArgError creates an ArgError for arguments of the action that are found invalid after parsing.
+func NewArgError(action string, err error) ArgError {
+	return ArgError{
+		Action: action,
+		error:  err,
+	}
+}
+
+// addFlags are the flags accepted by `todo -a` after the title
+var addFlags = []string{"-p", "--due", "--repeat", "--parent"}
+
 // Parse parses the arguments, checks for syntax, and returns error if any, or returns ParseResult otherwise
 func Parse(args []string) (*ParsedResult, error) {
 	p := newParser(args)
@@ -64,22 +124,56 @@ type parser struct {
 	readIdx int
 	arg     *string
 	args    []string
+	// now is the time relative dates are parsed against
+	now time.Time
 }
 
 // newParser creates a new argument parser
 func newParser(args []string) parser {
-	if len(args) == 0 {
-		// If no arguments are provided, default to list action
-		args = []string{"-l"}
-	}
-	p := parser{args: args}
+	p := parser{args: args, now: time.Now()}
 	p.read()
 	return p
 }
 
-// parse parses the arguments and returns the parsed action and values
+// parse parses the arguments and returns the parsed options, action and values
 func (p *parser) parse() (*ParsedResult, error) {
-	return p.parseAction()
+	options, err := p.parseGlobalOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *ParsedResult
+	if p.arg == nil {
+		// If no action is provided, default to list action
+		result = &ParsedResult{
+			Action: ActionList,
+			Values: nil,
+		}
+	} else if result, err = p.parseAction(); err != nil {
+		return nil, err
+	}
+	result.Options = options
+
+	return result, nil
+}
+
+// Parses the global options preceding the action, `[--file path]`
+func (p *parser) parseGlobalOptions() (ParsedValues, error) {
+	options := ParsedValues{}
+	for p.arg != nil {
+		switch *p.arg {
+		case "--file":
+			p.read()
+			if p.arg == nil {
+				return nil, fmt.Errorf("%w: file", ErrMissingArg)
+			}
+			options["file"] = *p.arg
+			p.read()
+		default:
+			return options, nil
+		}
+	}
+	return options, nil
 }
 
 // parseAction parses the action based on the first argument
@@ -99,6 +193,24 @@ func (p *parser) parseAction() (*ParsedResult, error) {
 		return p.parseMarkIncompleteAction()
 	case "-l":
 		return p.parseListAction()
+	case "-s":
+		return p.parseSearchAction()
+	case "repair":
+		return p.parseRepairAction()
+	case "undo":
+		return p.parseCountAction("undo", ActionUndo)
+	case "redo":
+		return p.parseCountAction("redo", ActionRedo)
+	case "trash":
+		return p.parseTrashAction()
+	case "archive":
+		return p.parseArchiveAction()
+	case "dep":
+		return p.parseDependencyAction()
+	case "export":
+		return p.parseExportAction()
+	case "import":
+		return p.parseImportAction()
 	default:
 		return nil, ErrUnsupportedAction
 	}
@@ -117,20 +229,384 @@ func (p *parser) parseHelpAction() (*ParsedResult, error) {
 	}, nil
 }
 
-// Parses `todo -l`
+// Parses `todo repair`
+func (p *parser) parseRepairAction() (*ParsedResult, error) {
+	err := p.checkFlag("repair")
+	if err != nil {
+		return nil, err
+	}
+
+	p.read()
+	if p.arg != nil {
+		return nil, ArgError{
+			Action: ActionRepair,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+
+	return &ParsedResult{
+		Action: ActionRepair,
+		Values: nil,
+	}, nil
+}
+
+// Parses `todo undo [n]` and `todo redo [n]`
+func (p *parser) parseCountAction(flag, action string) (*ParsedResult, error) {
+	err := p.checkFlag(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 1
+	p.read()
+	if p.arg != nil {
+		count, err = strconv.Atoi(*p.arg)
+		if err != nil || count < 1 {
+			return nil, ArgError{
+				Action: action,
+				error:  fmt.Errorf("%w: Expected a number of changes, got %s", ErrInvalidArg, *p.arg),
+			}
+		}
+		p.read()
+	}
+	if p.arg != nil {
+		return nil, ArgError{
+			Action: action,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+
+	return &ParsedResult{
+		Action: action,
+		Values: ParsedValues{
+			"count": count,
+		},
+	}, nil
+}
+
+// Parses `todo trash [--purge | --restore <id> [id2 id3 ...]]`
+func (p *parser) parseTrashAction() (*ParsedResult, error) {
+	err := p.checkFlag("trash")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ParsedResult{
+		Action: ActionTrash,
+		Values: ParsedValues{},
+	}
+
+	p.read()
+	if p.arg == nil {
+		return result, nil
+	}
+	switch *p.arg {
+	case "--purge":
+		result.Values["purge"] = true
+		p.read()
+		if p.arg != nil {
+			return nil, ArgError{
+				Action: ActionTrash,
+				error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+			}
+		}
+	case "--restore":
+		ids, err := p.readIds()
+		if err != nil {
+			return nil, ArgError{
+				Action: ActionTrash,
+				error:  err,
+			}
+		}
+		result.Values["restore"] = ids
+	default:
+		return nil, ArgError{
+			Action: ActionTrash,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+
+	return result, nil
+}
+
+// Parses `todo archive [--restore <id> [id2 id3 ...]]`
+func (p *parser) parseArchiveAction() (*ParsedResult, error) {
+	err := p.checkFlag("archive")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ParsedResult{
+		Action: ActionArchive,
+		Values: ParsedValues{},
+	}
+
+	p.read()
+	if p.arg == nil {
+		return result, nil
+	}
+	if *p.arg != "--restore" {
+		return nil, ArgError{
+			Action: ActionArchive,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+	ids, err := p.readIds()
+	if err != nil {
+		return nil, ArgError{
+			Action: ActionArchive,
+			error:  err,
+		}
+	}
+	result.Values["restore"] = ids
+
+	return result, nil
+}
+
+// Parses `todo dep add|rm <id> <blocker-id> [blocker-id2 ...]`
+func (p *parser) parseDependencyAction() (*ParsedResult, error) {
+	err := p.checkFlag("dep")
+	if err != nil {
+		return nil, err
+	}
+
+	p.read()
+	if p.arg == nil {
+		return nil, ArgError{
+			Action: ActionDependency,
+			error:  fmt.Errorf("%w: add or rm", ErrMissingArg),
+		}
+	}
+	if *p.arg != "add" && *p.arg != "rm" {
+		return nil, ArgError{
+			Action: ActionDependency,
+			error:  fmt.Errorf("%w: Expected add or rm, got %s", ErrInvalidArg, *p.arg),
+		}
+	}
+	remove := *p.arg == "rm"
+
+	ids, err := p.readIds()
+	if err == nil && len(ids) < 2 {
+		err = fmt.Errorf("%w: blocker id", ErrMissingArg)
+	}
+	if err != nil {
+		return nil, ArgError{
+			Action: ActionDependency,
+			error:  err,
+		}
+	}
+
+	return &ParsedResult{
+		Action: ActionDependency,
+		Values: ParsedValues{
+			"remove":   remove,
+			"id":       ids[0],
+			"blockers": ids[1:],
+		},
+	}, nil
+}
+
+// exportFormats maps the flags of `todo export` to the formats they pick
+var exportFormats = map[string]string{"--ical": "ical", "--markdown": "markdown"}
+
+// Parses `todo export --ical|--markdown`
+func (p *parser) parseExportAction() (*ParsedResult, error) {
+	err := p.checkFlag("export")
+	if err != nil {
+		return nil, err
+	}
+
+	p.read()
+	if p.arg == nil {
+		return nil, ArgError{
+			Action: ActionExport,
+			error:  fmt.Errorf("%w: format such as --ical or --markdown", ErrMissingArg),
+		}
+	}
+	format, ok := exportFormats[*p.arg]
+	if !ok {
+		return nil, ArgError{
+			Action: ActionExport,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+	p.read()
+	if p.arg != nil {
+		return nil, ArgError{
+			Action: ActionExport,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+
+	return &ParsedResult{
+		Action: ActionExport,
+		Values: ParsedValues{
+			"format": format,
+		},
+	}, nil
+}
+
+// Parses `todo import <file>`
+func (p *parser) parseImportAction() (*ParsedResult, error) {
+	err := p.checkFlag("import")
+	if err != nil {
+		return nil, err
+	}
+
+	p.read()
+	if p.arg == nil {
+		return nil, ArgError{
+			Action: ActionImport,
+			error:  fmt.Errorf("%w: file", ErrMissingArg),
+		}
+	}
+	path := *p.arg
+	p.read()
+	if p.arg != nil {
+		return nil, ArgError{
+			Action: ActionImport,
+			error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+		}
+	}
+
+	return &ParsedResult{
+		Action: ActionImport,
+		Values: ParsedValues{
+			"path": path,
+		},
+	}, nil
+}
+
+// Parses `todo -l [query...] [-p priority] [--completed period] [--sort keys] [--limit n] [--format f] [--template t] [--archived] [--ready]`
 func (p *parser) parseListAction() (*ParsedResult, error) {
 	err := p.checkFlag("-l")
 	if err != nil {
 		return nil, err
 	}
 
-	return &ParsedResult{
+	result := &ParsedResult{
 		Action: ActionList,
-		Values: nil,
+		Values: ParsedValues{},
+	}
+
+	p.read()
+	for p.arg != nil {
+		switch *p.arg {
+		case "-p":
+			priority, err := p.readPriority()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  err,
+				}
+			}
+			// -p filters by priority like a query term, but apart from the query so that it is not part of an `or`
+			term := "priority:none"
+			if priority != todo.PriorityNone {
+				term = "priority:" + string(priority)
+			}
+			result.appendValues("filters", []string{term})
+		case "--completed":
+			term, err := p.readCompleted()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  err,
+				}
+			}
+			result.appendValues("filters", []string{term})
+		case "--sort":
+			p.read()
+			if p.arg == nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  fmt.Errorf("%w: sort keys", ErrMissingArg),
+				}
+			}
+			result.Values["sort"] = *p.arg
+			p.read()
+		case "--limit":
+			p.read()
+			if p.arg == nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  fmt.Errorf("%w: limit", ErrMissingArg),
+				}
+			}
+			limit, err := strconv.Atoi(*p.arg)
+			if err != nil || limit < 0 {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  fmt.Errorf("%w: Expected limit as a number, got %s", ErrInvalidArg, *p.arg),
+				}
+			}
+			result.Values["limit"] = limit
+			p.read()
+		case "--format":
+			p.read()
+			if p.arg == nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  fmt.Errorf("%w: format", ErrMissingArg),
+				}
+			}
+			result.Values["format"] = strings.ToLower(*p.arg)
+			p.read()
+		case "--template":
+			p.read()
+			if p.arg == nil {
+				return nil, ArgError{
+					Action: ActionList,
+					error:  fmt.Errorf("%w: template", ErrMissingArg),
+				}
+			}
+			result.Values["template"] = *p.arg
+			p.read()
+		case "--archived":
+			result.Values["archived"] = true
+			p.read()
+		case "--ready":
+			result.Values["ready"] = true
+			p.read()
+		default:
+			result.appendValues("query", []string{*p.arg})
+			p.read()
+		}
+	}
+
+	return result, nil
+}
+
+// Parses `todo -s <term> [term2 term3 ...]`
+func (p *parser) parseSearchAction() (*ParsedResult, error) {
+	err := p.checkFlag("-s")
+	if err != nil {
+		return nil, err
+	}
+
+	p.read()
+	if p.arg == nil {
+		return nil, ArgError{
+			Action: ActionSearch,
+			error:  fmt.Errorf("%w: term", ErrMissingArg),
+		}
+	}
+
+	terms := []string{}
+	for p.arg != nil {
+		terms = append(terms, *p.arg)
+		p.read()
+	}
+
+	return &ParsedResult{
+		Action: ActionSearch,
+		Values: ParsedValues{
+			"terms": terms,
+		},
 	}, nil
 }
 
-// Parses `todo -a <title> [description]`
+// Parses `todo -a <title> [description] [-p priority] [--due date] [--repeat rule] [--parent id]`
 func (p *parser) parseAddAction() (*ParsedResult, error) {
 	err := p.checkFlag("-a")
 	if err != nil {
@@ -145,22 +621,87 @@ func (p *parser) parseAddAction() (*ParsedResult, error) {
 		}
 	}
 
+	title, err := parseTitle(*p.arg)
+	if err == nil && title.Title == "" {
+		err = fmt.Errorf("%w: title", ErrMissingArg)
+	}
+	if err != nil {
+		return nil, ArgError{
+			Action: ActionAdd,
+			error:  err,
+		}
+	}
+
 	result := &ParsedResult{
 		Action: ActionAdd,
 		Values: ParsedValues{
-			"title": *p.arg,
+			"title": title.Title,
 		},
 	}
+	if title.Project != "" {
+		result.Values["project"] = title.Project
+	}
+	if len(title.Tags) > 0 {
+		result.Values["tags"] = title.Tags
+	}
 
 	p.read()
-	if p.arg != nil {
+	if p.arg != nil && !p.isFlag(addFlags) {
 		result.Values["description"] = *p.arg
+		p.read()
+	}
+
+	for p.arg != nil {
+		switch *p.arg {
+		case "-p":
+			priority, err := p.readPriority()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionAdd,
+					error:  err,
+				}
+			}
+			result.Values["priority"] = priority
+		case "--due":
+			due, err := p.readDate()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionAdd,
+					error:  err,
+				}
+			}
+			result.Values["due"] = due
+		case "--repeat":
+			recurrence, err := p.readRecurrence()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionAdd,
+					error:  err,
+				}
+			}
+			result.Values["recurrence"] = recurrence
+		case "--parent":
+			p.read()
+			if p.arg == nil {
+				return nil, ArgError{
+					Action: ActionAdd,
+					error:  fmt.Errorf("%w: parent id", ErrMissingArg),
+				}
+			}
+			result.Values["parent"] = *p.arg
+			p.read()
+		default:
+			return nil, ArgError{
+				Action: ActionAdd,
+				error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+			}
+		}
 	}
 
 	return result, nil
 }
 
-// Parses `todo -u <id> [-t title] [-d description]`
+// Parses `todo -u <id> [-t title] [-d description] [-p priority] [--due date] [--repeat rule] [+project] [-+project] [@tag...] [-@tag...]`
 func (p *parser) parseUpdateAction() (*ParsedResult, error) {
 	err := p.checkFlag("-u")
 	if err != nil {
@@ -170,7 +711,7 @@ func (p *parser) parseUpdateAction() (*ParsedResult, error) {
 	p.read()
 	if p.arg == nil {
 		return nil, ArgError{
-			Action: ActionAdd,
+			Action: ActionUpdate,
 			error:  fmt.Errorf("%w: id", ErrMissingArg),
 		}
 	}
@@ -179,49 +720,100 @@ func (p *parser) parseUpdateAction() (*ParsedResult, error) {
 	p.read()
 	if p.arg == nil {
 		return nil, ArgError{
-			Action: ActionAdd,
-			error:  fmt.Errorf("%w: Expected -t or -d flag", ErrMissingArg),
+			Action: ActionUpdate,
+			error:  fmt.Errorf("%w: Expected -t, -d, -p, --due or --repeat flag, or +project or @tag", ErrMissingArg),
 		}
 	}
-	var title, description *string
+
+	result := &ParsedResult{
+		Action: ActionUpdate,
+		Values: ParsedValues{
+			"id": id,
+		},
+	}
 
 	for p.arg != nil {
-		if *p.arg == "-t" {
+		switch *p.arg {
+		case "-t":
 			p.read()
 			if p.arg == nil {
 				return nil, ArgError{
-					Action: ActionAdd,
+					Action: ActionUpdate,
 					error:  fmt.Errorf("%w: title", ErrMissingArg),
 				}
 			}
-			title = p.arg
+			title, err := parseTitle(*p.arg)
+			if err == nil && title.Title == "" {
+				err = fmt.Errorf("%w: title", ErrMissingArg)
+			}
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionUpdate,
+					error:  err,
+				}
+			}
+			result.Values["title"] = title.Title
+			if title.Project != "" {
+				result.Values["project"] = title.Project
+			}
+			result.appendValues("add_tags", title.Tags)
 			p.read()
-		} else if *p.arg == "-d" {
+		case "-d":
 			p.read()
 			if p.arg == nil {
 				return nil, ArgError{
-					Action: ActionAdd,
+					Action: ActionUpdate,
 					error:  fmt.Errorf("%w: description", ErrMissingArg),
 				}
 			}
-			description = p.arg
+			result.Values["description"] = *p.arg
+			p.read()
+		case "-p":
+			priority, err := p.readPriority()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionUpdate,
+					error:  err,
+				}
+			}
+			result.Values["priority"] = priority
+		case "--due":
+			due, err := p.readDate()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionUpdate,
+					error:  err,
+				}
+			}
+			result.Values["due"] = due
+		case "--repeat":
+			recurrence, err := p.readRecurrence()
+			if err != nil {
+				return nil, ArgError{
+					Action: ActionUpdate,
+					error:  err,
+				}
+			}
+			result.Values["recurrence"] = recurrence
+		default:
+			if project, ok := todo.ProjectToken(*p.arg); ok {
+				result.Values["project"] = project
+			} else if _, ok := todo.ProjectToken(strings.TrimPrefix(*p.arg, "-")); ok {
+				result.Values["project"] = ""
+			} else if tag, ok := todo.TagToken(*p.arg); ok {
+				result.appendValues("add_tags", []string{tag})
+			} else if tag, ok := todo.TagToken(strings.TrimPrefix(*p.arg, "-")); ok {
+				result.appendValues("remove_tags", []string{tag})
+			} else {
+				return nil, ArgError{
+					Action: ActionUpdate,
+					error:  fmt.Errorf("%w: %s", ErrUnknownArg, *p.arg),
+				}
+			}
 			p.read()
 		}
 	}
 
-	result := &ParsedResult{
-		Action: ActionUpdate,
-		Values: ParsedValues{
-			"id": id,
-		},
-	}
-	if title != nil {
-		result.Values["title"] = *title
-	}
-	if description != nil {
-		result.Values["description"] = *description
-	}
-
 	return result, nil
 }
 
@@ -322,6 +914,93 @@ func (p *parser) checkFlag(flag string) error {
 	return nil
 }
 
+// isFlag checks if the current argument is one of the flags
+func (p *parser) isFlag(flags []string) bool {
+	for _, flag := range flags {
+		if *p.arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// readPriority reads the next argument as a priority, and moves past it
+func (p *parser) readPriority() (todo.Priority, error) {
+	p.read()
+	if p.arg == nil {
+		return todo.PriorityNone, fmt.Errorf("%w: priority", ErrMissingArg)
+	}
+	priority, err := todo.ParsePriority(*p.arg)
+	if err != nil {
+		return todo.PriorityNone, fmt.Errorf("%w: %s", ErrInvalidArg, err)
+	}
+	p.read()
+	return priority, nil
+}
+
+// readRecurrence reads the next argument as a recurrence rule, and moves past it. `none` stops repeating.
+func (p *parser) readRecurrence() (todo.Recurrence, error) {
+	p.read()
+	if p.arg == nil {
+		return todo.RecurrenceNone, fmt.Errorf("%w: recurrence", ErrMissingArg)
+	}
+	recurrence, err := todo.ParseRecurrence(*p.arg)
+	if err != nil {
+		return todo.RecurrenceNone, fmt.Errorf("%w: %s", ErrInvalidArg, err)
+	}
+	p.read()
+	return recurrence, nil
+}
+
+// parseTitle splits the `+project` and `@tag` tokens out of a title as todo.ParseTitle does. A title can name at
+// most one project.
+func parseTitle(title string) (todo.ParsedTitle, error) {
+	parsed := todo.ParseTitle(title)
+	if len(parsed.OtherProjects) > 0 {
+		return todo.ParsedTitle{}, fmt.Errorf("Expected at most one project, got +%s and +%s", parsed.Project, parsed.OtherProjects[0])
+	}
+	return parsed, nil
+}
+
+// readCompleted reads the next argument as the period of `--completed`, `today` or `week`, and moves past it.
+// It returns the query term matching todos completed in the period, where weeks start on Monday.
+func (p *parser) readCompleted() (string, error) {
+	p.read()
+	if p.arg == nil {
+		return "", fmt.Errorf("%w: period", ErrMissingArg)
+	}
+	today := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+	var since time.Time
+	switch strings.ToLower(*p.arg) {
+	case "today":
+		since = today
+	case "week":
+		since = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	default:
+		return "", fmt.Errorf("%w: Expected period as today or week, got %s", ErrInvalidArg, *p.arg)
+	}
+	p.read()
+	return "completed>=" + since.Format("2006-01-02"), nil
+}
+
+// readDate reads the next argument as a date, and moves past it. `none` clears the date with a zero time.
+func (p *parser) readDate() (time.Time, error) {
+	p.read()
+	if p.arg == nil {
+		return time.Time{}, fmt.Errorf("%w: date", ErrMissingArg)
+	}
+	if *p.arg == "none" {
+		p.read()
+		return time.Time{}, nil
+	}
+	date, err := todo.ParseDate(*p.arg, p.now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidArg, err)
+	}
+	p.read()
+	return date, nil
+}
+
 // readIds reads the rest of the arguments as ids
 func (p *parser) readIds() ([]string, error) {
 	p.read()
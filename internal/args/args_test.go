@@ -1,8 +1,12 @@
 package args
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
 
-// TODO: Add failure test cases
+	"github.com/hwkd/todo-cli/internal/todo"
+)
 
 func TestParsingHelp(t *testing.T) {
 	tests := []struct {
@@ -142,6 +146,29 @@ func TestParsingAdd(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Add title and priority",
+			[]string{"-a", "hello", "-p", "high"},
+			ParsedResult{
+				Action: ActionAdd,
+				Values: ParsedValues{
+					"title":    "hello",
+					"priority": todo.Priority("A"),
+				},
+			},
+		},
+		{
+			"Add title, description and priority",
+			[]string{"-a", "hello", "world", "-p", "c"},
+			ParsedResult{
+				Action: ActionAdd,
+				Values: ParsedValues{
+					"title":       "hello",
+					"description": "world",
+					"priority":    todo.Priority("C"),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,7 +184,7 @@ func TestParsingAdd(t *testing.T) {
 				return
 			}
 
-			for _, field := range []string{"title", "description"} {
+			for _, field := range []string{"title", "description", "priority"} {
 				if value, ok := tt.want.Values[field]; ok {
 					if result.Values[field] != value {
 						t.Errorf("Expected %s, got %s", value, result.Values[field])
@@ -212,6 +239,17 @@ func TestParsingUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "Clear priority",
+			input: []string{"-u", "4", "-p", "none"},
+			want: ParsedResult{
+				Action: ActionUpdate,
+				Values: ParsedValues{
+					"id":       "4",
+					"priority": todo.PriorityNone,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -227,7 +265,7 @@ func TestParsingUpdate(t *testing.T) {
 				return
 			}
 
-			for _, field := range []string{"id", "title", "description"} {
+			for _, field := range []string{"id", "title", "description", "priority"} {
 				if value, ok := tt.want.Values[field]; ok {
 					if result.Values[field] != value {
 						t.Errorf("Expected %s, got %s", value, result.Values[field])
@@ -382,3 +420,452 @@ func TestParsingMarkIncomplete(t *testing.T) {
 		})
 	}
 }
+
+func TestParsingGlobalOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []string
+		action string
+		file   string
+	}{
+		{
+			"No options",
+			[]string{"-l"},
+			ActionList,
+			"",
+		},
+		{
+			"File without action should default to list",
+			[]string{"--file", "work.csv"},
+			ActionList,
+			"work.csv",
+		},
+		{
+			"File before action",
+			[]string{"--file", "work.json", "-a", "some title"},
+			ActionAdd,
+			"work.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.input)
+			if err != nil {
+				t.Errorf("Expected nil, got `%s`", err)
+				return
+			}
+
+			if result.Action != tt.action {
+				t.Errorf("Expected %s, got %s", tt.action, result.Action)
+				return
+			}
+
+			if file := result.ParseGlobalOptions().File; file != tt.file {
+				t.Errorf("Expected %s, got %s", tt.file, file)
+				return
+			}
+		})
+	}
+}
+
+func TestParsingFailures(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+	}{
+		{"Add without title", []string{"-a"}},
+		{"Add with invalid priority", []string{"-a", "hello", "-p", "urgent"}},
+		{"Update with unknown flag", []string{"-u", "1", "-x", "abc"}},
+		{"List with missing priority", []string{"-l", "-p"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParsingDue(t *testing.T) {
+	result, err := Parse([]string{"-a", "hello", "--due", "2026-11-01 17:00"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	want := time.Date(2026, 11, 1, 17, 0, 0, 0, time.Local)
+	if due := result.ParseAddActionValues().DueAt; !due.Equal(want) {
+		t.Errorf("Expected %s, got %s", want, due)
+	}
+
+	result, err = Parse([]string{"-u", "1", "--due", "none"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if due := result.ParseUpdateActionValues().DueAt; due == nil || !due.IsZero() {
+		t.Errorf("Expected zero time, got %v", due)
+	}
+
+	if _, err := Parse([]string{"-a", "hello", "--due", "someday"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestParsingParent(t *testing.T) {
+	result, err := Parse([]string{"-a", "Book venue", "--parent", "3", "-p", "A"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if parent := result.ParseAddActionValues().Parent; parent != "3" {
+		t.Errorf("Expected %s, got %s", "3", parent)
+	}
+	if _, err := Parse([]string{"-a", "Book venue", "--parent"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestParsingRepeat(t *testing.T) {
+	result, err := Parse([]string{"-a", "Water plants", "--repeat", "weekly:mon,thu", "--due", "mon"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if recurrence := result.ParseAddActionValues().Recurrence; recurrence != "FREQ=WEEKLY;BYDAY=MO,TH" {
+		t.Errorf("Expected %s, got %s", "FREQ=WEEKLY;BYDAY=MO,TH", recurrence)
+	}
+
+	result, err = Parse([]string{"-u", "1", "--repeat", "none"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if recurrence := result.ParseUpdateActionValues().Recurrence; recurrence == nil || *recurrence != todo.RecurrenceNone {
+		t.Errorf("Expected none, got %v", recurrence)
+	}
+
+	for _, input := range [][]string{{"-a", "hello", "--repeat"}, {"-u", "1", "--repeat", "sometimes"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingCompleted(t *testing.T) {
+	tests := []struct {
+		period string
+		want   string
+	}{
+		{"today", "completed>=2026-10-14"},
+		{"week", "completed>=2026-10-12"},
+		{"WEEK", "completed>=2026-10-12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			p := newParser([]string{"-l", "--completed", tt.period})
+			// Wednesday
+			p.now = time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local)
+			result, err := p.parse()
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			filters := result.ParseListActionValues().Filters
+			if len(filters) != 1 || filters[0] != tt.want {
+				t.Errorf("Expected [%s], got %v", tt.want, filters)
+			}
+		})
+	}
+
+	for _, input := range [][]string{{"-l", "--completed"}, {"-l", "--completed", "month"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingTags(t *testing.T) {
+	result, err := Parse([]string{"-a", "Call Bob +work @phone @errand"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	add := result.ParseAddActionValues()
+	if add.Title != "Call Bob" {
+		t.Errorf("Expected %s, got %s", "Call Bob", add.Title)
+	}
+	if add.Project != "work" {
+		t.Errorf("Expected %s, got %s", "work", add.Project)
+	}
+	if len(add.Tags) != 2 || add.Tags[0] != "phone" || add.Tags[1] != "errand" {
+		t.Errorf("Expected [phone errand], got %v", add.Tags)
+	}
+
+	result, err = Parse([]string{"-u", "1", "-+work", "@home", "-@phone"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	update := result.ParseUpdateActionValues()
+	if update.Project == nil || *update.Project != "" {
+		t.Errorf("Expected empty project, got %v", update.Project)
+	}
+	if len(update.AddTags) != 1 || update.AddTags[0] != "home" {
+		t.Errorf("Expected [home], got %v", update.AddTags)
+	}
+	if len(update.RemoveTags) != 1 || update.RemoveTags[0] != "phone" {
+		t.Errorf("Expected [phone], got %v", update.RemoveTags)
+	}
+
+	result, err = Parse([]string{"-a", "Call  Bob +work at 10:00 @phone"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if title := result.ParseAddActionValues().Title; title != "Call  Bob at 10:00" {
+		t.Errorf("Expected %q, got %q", "Call  Bob at 10:00", title)
+	}
+
+	for _, input := range [][]string{
+		{"-a", "+work @phone"},
+		{"-a", "Call Bob +work +home"},
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingListQuery(t *testing.T) {
+	result, err := Parse([]string{"-l", "done:false", "+work", "-p", "A", "--sort", "due,-priority", "--limit", "5", "--format", "JSON", "--template", "{{.ID}}", "--archived", "--ready"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	values := result.ParseListActionValues()
+	want := []string{"done:false", "+work"}
+	if len(values.Query) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, values.Query)
+	}
+	for i := range want {
+		if values.Query[i] != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], values.Query[i])
+		}
+	}
+	if len(values.Filters) != 1 || values.Filters[0] != "priority:A" {
+		t.Errorf("Expected [priority:A], got %v", values.Filters)
+	}
+	if values.Sort != "due,-priority" {
+		t.Errorf("Expected %s, got %s", "due,-priority", values.Sort)
+	}
+	if values.Limit != 5 {
+		t.Errorf("Expected %d, got %d", 5, values.Limit)
+	}
+	if values.Format != "json" {
+		t.Errorf("Expected %s, got %s", "json", values.Format)
+	}
+	if values.Template != "{{.ID}}" {
+		t.Errorf("Expected %s, got %s", "{{.ID}}", values.Template)
+	}
+	if !values.Archived || !values.Ready {
+		t.Errorf("Expected archived and ready, got %v and %v", values.Archived, values.Ready)
+	}
+
+	if _, err := Parse([]string{"-l", "--limit", "many"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	if _, err := Parse([]string{"-l", "--format"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestParsingRepair(t *testing.T) {
+	result, err := Parse([]string{"repair"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionRepair {
+		t.Errorf("Expected %s, got %s", ActionRepair, result.Action)
+	}
+
+	if _, err := Parse([]string{"repair", "now"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestParsingUndoRedo(t *testing.T) {
+	tests := []struct {
+		input  []string
+		action string
+		count  int
+	}{
+		{[]string{"undo"}, ActionUndo, 1},
+		{[]string{"undo", "3"}, ActionUndo, 3},
+		{[]string{"redo"}, ActionRedo, 1},
+		{[]string{"redo", "2"}, ActionRedo, 2},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.input, " "), func(t *testing.T) {
+			result, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			if result.Action != tt.action {
+				t.Errorf("Expected %s, got %s", tt.action, result.Action)
+			}
+			count := result.ParseUndoActionValues().Count
+			if tt.action == ActionRedo {
+				count = result.ParseRedoActionValues().Count
+			}
+			if count != tt.count {
+				t.Errorf("Expected %d, got %d", tt.count, count)
+			}
+		})
+	}
+
+	for _, input := range [][]string{{"undo", "0"}, {"undo", "all"}, {"redo", "1", "2"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	}
+}
+
+func TestParsingTrash(t *testing.T) {
+	tests := []struct {
+		input   []string
+		purge   bool
+		restore []string
+	}{
+		{[]string{"trash"}, false, nil},
+		{[]string{"trash", "--purge"}, true, nil},
+		{[]string{"trash", "--restore", "3", "a1b2"}, false, []string{"3", "a1b2"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.input, " "), func(t *testing.T) {
+			result, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			if result.Action != ActionTrash {
+				t.Errorf("Expected %s, got %s", ActionTrash, result.Action)
+			}
+			values := result.ParseTrashActionValues()
+			if values.Purge != tt.purge {
+				t.Errorf("Expected %t, got %t", tt.purge, values.Purge)
+			}
+			if strings.Join(values.Restore, " ") != strings.Join(tt.restore, " ") {
+				t.Errorf("Expected %v, got %v", tt.restore, values.Restore)
+			}
+		})
+	}
+
+	for _, input := range [][]string{{"trash", "--restore"}, {"trash", "--purge", "3"}, {"trash", "3"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingArchive(t *testing.T) {
+	result, err := Parse([]string{"archive"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionArchive {
+		t.Errorf("Expected %s, got %s", ActionArchive, result.Action)
+	}
+	if restore := result.ParseArchiveActionValues().Restore; len(restore) != 0 {
+		t.Errorf("Expected [], got %v", restore)
+	}
+
+	result, err = Parse([]string{"archive", "--restore", "3", "a1b2"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if restore := result.ParseArchiveActionValues().Restore; strings.Join(restore, " ") != "3 a1b2" {
+		t.Errorf("Expected [3 a1b2], got %v", restore)
+	}
+
+	for _, input := range [][]string{{"archive", "--restore"}, {"archive", "3"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingDependency(t *testing.T) {
+	result, err := Parse([]string{"dep", "add", "3", "1", "a1b2"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionDependency {
+		t.Errorf("Expected %s, got %s", ActionDependency, result.Action)
+	}
+	values := result.ParseDependencyActionValues()
+	if values.Remove || values.ID != "3" || strings.Join(values.Blockers, " ") != "1 a1b2" {
+		t.Errorf("Expected add 3 [1 a1b2], got %+v", values)
+	}
+
+	result, err = Parse([]string{"dep", "rm", "3", "1"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if values := result.ParseDependencyActionValues(); !values.Remove {
+		t.Errorf("Expected remove, got %+v", values)
+	}
+
+	for _, input := range [][]string{{"dep"}, {"dep", "add"}, {"dep", "add", "3"}, {"dep", "list", "3", "1"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingExportImport(t *testing.T) {
+	result, err := Parse([]string{"export", "--ical"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionExport {
+		t.Errorf("Expected %s, got %s", ActionExport, result.Action)
+	}
+	if format := result.ParseExportActionValues().Format; format != "ical" {
+		t.Errorf("Expected %s, got %s", "ical", format)
+	}
+
+	result, err = Parse([]string{"export", "--markdown"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if format := result.ParseExportActionValues().Format; format != "markdown" {
+		t.Errorf("Expected %s, got %s", "markdown", format)
+	}
+
+	result, err = Parse([]string{"import", "tasks.ics"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionImport {
+		t.Errorf("Expected %s, got %s", ActionImport, result.Action)
+	}
+	if path := result.ParseImportActionValues().Path; path != "tasks.ics" {
+		t.Errorf("Expected %s, got %s", "tasks.ics", path)
+	}
+
+	for _, input := range [][]string{{"export"}, {"export", "--pdf"}, {"export", "--ical", "x"}, {"import"}, {"import", "a.ics", "b.ics"}} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Expected error for %v, got nil", input)
+		}
+	}
+}
+
+func TestParsingSearch(t *testing.T) {
+	result, err := Parse([]string{"-s", "deploy", "app"})
+	if err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if result.Action != ActionSearch {
+		t.Errorf("Expected %s, got %s", ActionSearch, result.Action)
+	}
+	terms := result.ParseSearchActionValues().Terms
+	if len(terms) != 2 || terms[0] != "deploy" || terms[1] != "app" {
+		t.Errorf("Expected [deploy app], got %v", terms)
+	}
+
+	if _, err := Parse([]string{"-s"}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
@@ -1,16 +1,60 @@
 package args
 
+import (
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// ParsedListActionValues is a struct that holds the parsed values of the list action.
+// Query holds the terms of the filter query, and Filters the terms of -p and --completed, which each must match
+// as well as the whole query. Sort holds the unparsed sort keys, Limit is 0 for no limit, Format is
+// the output format, empty for the default, and Template the name or text of a template rendering each item.
+// Archived lists the archived todos instead, and Ready only the incomplete todos that are not blocked.
+type ParsedListActionValues struct {
+	Query    []string
+	Filters  []string
+	Sort     string
+	Limit    int
+	Format   string
+	Template string
+	Archived bool
+	Ready    bool
+}
+
+// ParsedSearchActionValues is a struct that holds the parsed values of the search action.
+type ParsedSearchActionValues struct {
+	Terms []string
+}
+
 // ParsedAddActionValues is a struct that holds the parsed values of the add action.
 type ParsedAddActionValues struct {
 	Title       string
 	Description string
+	Priority    todo.Priority
+	DueAt       time.Time
+	Recurrence  todo.Recurrence
+	Project     string
+	Tags        []string
+	// Parent is the id of the todo to add a subtask to, as given.
+	Parent string
 }
 
 // ParsedUpdateActionValues is a struct that holds the parsed values of the update action.
+// Fields are nil when they were not given and should be left unchanged.
 type ParsedUpdateActionValues struct {
 	ID          string
-	Title       string
-	Description string
+	Title       *string
+	Description *string
+	Priority    *todo.Priority
+	// DueAt points to a zero time when the due date should be cleared.
+	DueAt *time.Time
+	// Recurrence points to RecurrenceNone when the todo should stop repeating.
+	Recurrence *todo.Recurrence
+	// Project points to an empty string when the project should be cleared.
+	Project    *string
+	AddTags    []string
+	RemoveTags []string
 }
 
 // ParsedIdValues is a struct that holds the parsed ids in the command line arguments.
@@ -18,12 +62,111 @@ type ParsedIdValues struct {
 	IDs []string
 }
 
+// ParsedCountValues is a struct that holds the number of changes to undo or redo.
+type ParsedCountValues struct {
+	Count int
+}
+
+// ParsedTrashActionValues is a struct that holds the parsed values of the trash action. The trash is listed
+// unless Purge is set or Restore holds the ids of todos to restore.
+type ParsedTrashActionValues struct {
+	Purge   bool
+	Restore []string
+}
+
+// ParsedArchiveActionValues is a struct that holds the parsed values of the archive action. Done todos are
+// archived unless Restore holds the ids of todos to restore.
+type ParsedArchiveActionValues struct {
+	Restore []string
+}
+
+// ParsedDependencyActionValues is a struct that holds the parsed values of the dependency action. The todo
+// with the id depends on the Blockers, or stops depending on them if Remove is set.
+type ParsedDependencyActionValues struct {
+	Remove   bool
+	ID       string
+	Blockers []string
+}
+
+// ParsedExportActionValues is a struct that holds the parsed values of the export action. Format is the
+// format to write the todos in, `ical` or `markdown`.
+type ParsedExportActionValues struct {
+	Format string
+}
+
+// ParsedImportActionValues is a struct that holds the parsed values of the import action. Path is the file to
+// read todos from, in the format matching its extension.
+type ParsedImportActionValues struct {
+	Path string
+}
+
+// ParsedGlobalOptions is a struct that holds the parsed options that apply to every action.
+type ParsedGlobalOptions struct {
+	File string
+}
+
 type ParsedValues map[string]interface{}
 
-// ParsedResult is a struct that holds the parsed action and values of the command line arguments.
+// ParsedResult is a struct that holds the parsed global options, action and values of the command line arguments.
 type ParsedResult struct {
-	Action string
-	Values ParsedValues
+	Action  string
+	Values  ParsedValues
+	Options ParsedValues
+}
+
+// appendValues appends to the list of strings stored under the key.
+func (r *ParsedResult) appendValues(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	existing, _ := r.Values[key].([]string)
+	r.Values[key] = append(existing, values...)
+}
+
+// ParseGlobalOptions wraps the parsed global options in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseGlobalOptions() ParsedGlobalOptions {
+	options := ParsedGlobalOptions{}
+	if file, ok := r.Options["file"]; ok {
+		options.File = file.(string)
+	}
+	return options
+}
+
+// ParseListActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseListActionValues() ParsedListActionValues {
+	values := ParsedListActionValues{}
+	if query, ok := r.Values["query"]; ok {
+		values.Query = query.([]string)
+	}
+	if filters, ok := r.Values["filters"]; ok {
+		values.Filters = filters.([]string)
+	}
+	if sort, ok := r.Values["sort"]; ok {
+		values.Sort = sort.(string)
+	}
+	if limit, ok := r.Values["limit"]; ok {
+		values.Limit = limit.(int)
+	}
+	if format, ok := r.Values["format"]; ok {
+		values.Format = format.(string)
+	}
+	if template, ok := r.Values["template"]; ok {
+		values.Template = template.(string)
+	}
+	if archived, ok := r.Values["archived"]; ok {
+		values.Archived = archived.(bool)
+	}
+	if ready, ok := r.Values["ready"]; ok {
+		values.Ready = ready.(bool)
+	}
+	return values
+}
+
+// ParseSearchActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseSearchActionValues() ParsedSearchActionValues {
+	return ParsedSearchActionValues{
+		Terms: r.Values["terms"].([]string),
+	}
 }
 
 // ParseAddActionValues wraps the parsed values in a typed struct for ease of use and safety.
@@ -34,6 +177,24 @@ func (r *ParsedResult) ParseAddActionValues() ParsedAddActionValues {
 	if desc, ok := r.Values["description"]; ok {
 		values.Description = desc.(string)
 	}
+	if priority, ok := r.Values["priority"]; ok {
+		values.Priority = priority.(todo.Priority)
+	}
+	if due, ok := r.Values["due"]; ok {
+		values.DueAt = due.(time.Time)
+	}
+	if recurrence, ok := r.Values["recurrence"]; ok {
+		values.Recurrence = recurrence.(todo.Recurrence)
+	}
+	if project, ok := r.Values["project"]; ok {
+		values.Project = project.(string)
+	}
+	if tags, ok := r.Values["tags"]; ok {
+		values.Tags = tags.([]string)
+	}
+	if parent, ok := r.Values["parent"]; ok {
+		values.Parent = parent.(string)
+	}
 	return values
 }
 
@@ -43,10 +204,34 @@ func (r *ParsedResult) ParseUpdateActionValues() ParsedUpdateActionValues {
 		ID: r.Values["id"].(string),
 	}
 	if title, ok := r.Values["title"]; ok {
-		values.Title = title.(string)
+		t := title.(string)
+		values.Title = &t
 	}
 	if desc, ok := r.Values["description"]; ok {
-		values.Description = desc.(string)
+		d := desc.(string)
+		values.Description = &d
+	}
+	if priority, ok := r.Values["priority"]; ok {
+		p := priority.(todo.Priority)
+		values.Priority = &p
+	}
+	if due, ok := r.Values["due"]; ok {
+		d := due.(time.Time)
+		values.DueAt = &d
+	}
+	if recurrence, ok := r.Values["recurrence"]; ok {
+		rec := recurrence.(todo.Recurrence)
+		values.Recurrence = &rec
+	}
+	if project, ok := r.Values["project"]; ok {
+		p := project.(string)
+		values.Project = &p
+	}
+	if tags, ok := r.Values["add_tags"]; ok {
+		values.AddTags = tags.([]string)
+	}
+	if tags, ok := r.Values["remove_tags"]; ok {
+		values.RemoveTags = tags.([]string)
 	}
 	return values
 }
@@ -71,3 +256,61 @@ func (r *ParsedResult) ParseMarkIncompleteActionValues() ParsedIdValues {
 		IDs: r.Values["ids"].([]string),
 	}
 }
+
+// ParseUndoActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseUndoActionValues() ParsedCountValues {
+	return ParsedCountValues{
+		Count: r.Values["count"].(int),
+	}
+}
+
+// ParseRedoActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseRedoActionValues() ParsedCountValues {
+	return ParsedCountValues{
+		Count: r.Values["count"].(int),
+	}
+}
+
+// ParseTrashActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseTrashActionValues() ParsedTrashActionValues {
+	values := ParsedTrashActionValues{}
+	if purge, ok := r.Values["purge"]; ok {
+		values.Purge = purge.(bool)
+	}
+	if restore, ok := r.Values["restore"]; ok {
+		values.Restore = restore.([]string)
+	}
+	return values
+}
+
+// ParseArchiveActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseArchiveActionValues() ParsedArchiveActionValues {
+	values := ParsedArchiveActionValues{}
+	if restore, ok := r.Values["restore"]; ok {
+		values.Restore = restore.([]string)
+	}
+	return values
+}
+
+// ParseDependencyActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseDependencyActionValues() ParsedDependencyActionValues {
+	return ParsedDependencyActionValues{
+		Remove:   r.Values["remove"].(bool),
+		ID:       r.Values["id"].(string),
+		Blockers: r.Values["blockers"].([]string),
+	}
+}
+
+// ParseExportActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseExportActionValues() ParsedExportActionValues {
+	return ParsedExportActionValues{
+		Format: r.Values["format"].(string),
+	}
+}
+
+// ParseImportActionValues wraps the parsed values in a typed struct for ease of use and safety.
+func (r *ParsedResult) ParseImportActionValues() ParsedImportActionValues {
+	return ParsedImportActionValues{
+		Path: r.Values["path"].(string),
+	}
+}
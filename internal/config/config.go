@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvFile is the environment variable that overrides the todo file.
+const EnvFile = "TODO_FILE"
+
+// DefaultTrashRetention is how long deleted todos stay in the trash when the config file doesn't say.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// DefaultFileName is the name of the global todo file under the data directory.
+const DefaultFileName = "todo.csv"
+
+// ProjectFileNames are the file names looked up from the working directory upwards to find a per-project
+// todo file, in order of preference.
+var ProjectFileNames = []string{".todo.csv", ".todo.json", ".todo.db", ".todo.txt"}
+
+// Config holds the settings read from the config file.
+type Config struct {
+	// File is the todo file to use when neither the flag, the environment, nor a project file picks one.
+	// Relative paths are resolved against the config directory.
+	File string `json:"file"`
+	// Templates are named list templates, used by `todo -l --template name`.
+	Templates map[string]string `json:"templates"`
+	// TrashRetentionDays is the number of days deleted todos stay in the trash before they are purged. 0 keeps
+	// them for DefaultTrashRetention, and a negative number keeps them until `todo trash --purge`.
+	TrashRetentionDays int `json:"trash_retention_days"`
+	// ArchiveAfterDays is the number of days after which done todos are moved to the archive. 0 leaves them in
+	// the list until `todo archive`.
+	ArchiveAfterDays int `json:"archive_after_days"`
+
+	// dir is the directory the config file was read from.
+	dir string
+}
+
+// Dir returns the directory holding the config file, `$XDG_CONFIG_HOME/todo`.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "todo"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todo"), nil
+}
+
+// DataDir returns the directory holding the global todo file, `$XDG_DATA_HOME/todo`.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "todo"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "todo"), nil
+}
+
+// Load reads `config.json` from the config directory. A missing file results in an empty Config.
+func Load() (*Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(filepath.Join(dir, "config.json"))
+}
+
+// LoadFile reads the config file at path. A missing file results in an empty Config.
+func LoadFile(path string) (*Config, error) {
+	config := &Config{dir: filepath.Dir(path)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%w: Error reading config file %s", err, path)
+	}
+	return config, nil
+}
+
+// TodoFile returns the path of the todo file to use. In order of precedence, it is:
+//   - flag, the value of the `--file` flag
+//   - the TODO_FILE environment variable
+//   - a per-project file such as `.todo.csv` in the working directory or the closest parent containing one
+//   - the `file` setting of the config file
+//   - `todo.csv` in the data directory
+func (c *Config) TodoFile(flag string) (string, error) {
+	if flag != "" {
+		return expandHome(flag)
+	}
+	if file := os.Getenv(EnvFile); file != "" {
+		return expandHome(file)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if file, ok := findProjectFile(cwd); ok {
+		return file, nil
+	}
+
+	if c.File != "" {
+		file, err := expandHome(c.File)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(c.dir, file)
+		}
+		return file, nil
+	}
+
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultFileName), nil
+}
+
+// Template returns the list template named name, or name itself if no template has that name, so that
+// templates can be given inline.
+func (c *Config) Template(name string) string {
+	if template, ok := c.Templates[name]; ok {
+		return template
+	}
+	return name
+}
+
+// TrashRetention returns how long deleted todos stay in the trash, and false if they are never purged
+// automatically.
+func (c *Config) TrashRetention() (time.Duration, bool) {
+	switch {
+	case c.TrashRetentionDays < 0:
+		return 0, false
+	case c.TrashRetentionDays == 0:
+		return DefaultTrashRetention, true
+	default:
+		return time.Duration(c.TrashRetentionDays) * 24 * time.Hour, true
+	}
+}
+
+// ArchiveAfter returns how long done todos stay in the list, and false if they are never archived
+// automatically.
+func (c *Config) ArchiveAfter() (time.Duration, bool) {
+	if c.ArchiveAfterDays <= 0 {
+		return 0, false
+	}
+	return time.Duration(c.ArchiveAfterDays) * 24 * time.Hour, true
+}
+
+// findProjectFile walks up from dir and returns the first per-project todo file it finds.
+func findProjectFile(dir string) (string, bool) {
+	for {
+		for _, name := range ProjectFileNames {
+			file := filepath.Join(dir, name)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// expandHome replaces a leading `~` in path with the user's home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
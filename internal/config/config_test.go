@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTodoFile(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	nested := filepath.Join(project, "src", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(nested); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv(EnvFile, "")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	t.Run("Default to data directory", func(t *testing.T) {
+		file, err := config.TodoFile("")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		want := filepath.Join(root, "data", "todo", DefaultFileName)
+		if file != want {
+			t.Errorf("Expected %s, got %s", want, file)
+		}
+	})
+
+	t.Run("Config file setting", func(t *testing.T) {
+		config := &Config{File: "work.csv", dir: filepath.Join(root, "config", "todo")}
+		file, err := config.TodoFile("")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		want := filepath.Join(root, "config", "todo", "work.csv")
+		if file != want {
+			t.Errorf("Expected %s, got %s", want, file)
+		}
+	})
+
+	projectFile := filepath.Join(project, ".todo.csv")
+	if err := os.WriteFile(projectFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Project file in a parent directory", func(t *testing.T) {
+		config := &Config{File: "/elsewhere/todo.csv"}
+		file, err := config.TodoFile("")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if file != projectFile {
+			t.Errorf("Expected %s, got %s", projectFile, file)
+		}
+	})
+
+	t.Run("Environment overrides project file", func(t *testing.T) {
+		t.Setenv(EnvFile, "/env/todo.csv")
+		file, err := config.TodoFile("")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if file != "/env/todo.csv" {
+			t.Errorf("Expected %s, got %s", "/env/todo.csv", file)
+		}
+	})
+
+	t.Run("Flag overrides everything", func(t *testing.T) {
+		t.Setenv(EnvFile, "/env/todo.csv")
+		file, err := config.TodoFile("/flag/todo.csv")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if file != "/flag/todo.csv" {
+			t.Errorf("Expected %s, got %s", "/flag/todo.csv", file)
+		}
+	})
+}
+
+func TestTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"templates": {"brief": "{{.ID | short}} {{.Title}}"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if got := config.Template("brief"); got != "{{.ID | short}} {{.Title}}" {
+		t.Errorf("Expected %s, got %s", "{{.ID | short}} {{.Title}}", got)
+	}
+	if got := config.Template("{{.Title}}"); got != "{{.Title}}" {
+		t.Errorf("Expected %s, got %s", "{{.Title}}", got)
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	tests := []struct {
+		days      int
+		retention time.Duration
+		ok        bool
+	}{
+		{0, DefaultTrashRetention, true},
+		{7, 7 * 24 * time.Hour, true},
+		{-1, 0, false},
+	}
+	for _, tt := range tests {
+		retention, ok := (&Config{TrashRetentionDays: tt.days}).TrashRetention()
+		if retention != tt.retention || ok != tt.ok {
+			t.Errorf("Expected %s, %t, got %s, %t", tt.retention, tt.ok, retention, ok)
+		}
+	}
+}
+
+func TestArchiveAfter(t *testing.T) {
+	if _, ok := (&Config{}).ArchiveAfter(); ok {
+		t.Errorf("Expected false, got true")
+	}
+	after, ok := (&Config{ArchiveAfterDays: 14}).ArchiveAfter()
+	if after != 14*24*time.Hour || !ok {
+		t.Errorf("Expected %s, true, got %s, %t", 14*24*time.Hour, after, ok)
+	}
+}
@@ -0,0 +1,434 @@
+// Package ical translates todo items to and from iCalendar VTODO components, as defined by RFC 5545, so they
+// can be moved between the CLI and calendar apps.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+var ErrInvalidCalendar = errors.New("Invalid iCalendar file")
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+	// lineLength is the length in octets past which content lines are folded.
+	lineLength = 75
+)
+
+// todoIDPattern matches the IDs assigned to todos, which are used as UIDs as they are.
+var todoIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// Encode writes the todos as a VCALENDAR of VTODO components stamped at now. The UID of each VTODO is the
+// ID of its todo, and:
+//   - SUMMARY and DESCRIPTION are the title and description
+//   - STATUS is COMPLETED or NEEDS-ACTION, and COMPLETED when the todo was completed
+//   - DUE is a date, or a date and time in UTC
+//   - CREATED and LAST-MODIFIED are the creation and update times
+//   - PRIORITY is 1 for A, 5 for B and 9 for lower priorities
+//   - CATEGORIES are the tags, and RRULE the recurrence
+//   - X-PROJECT is the project, which iCalendar doesn't define
+//   - RELATED-TO is the parent of a subtask, and, with RELTYPE=DEPENDS-ON, the todos it depends on
+func Encode(w io.Writer, todos []todo.TodoItem, now time.Time) error {
+	writer := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(writer, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//hwkd//todo-cli//EN")
+	for _, todoItem := range todos {
+		line("BEGIN", "VTODO")
+		line("UID", todoItem.ID)
+		line("DTSTAMP", now.UTC().Format(utcLayout))
+		line("SUMMARY", escape(todoItem.Title))
+		if todoItem.Description != "" {
+			line("DESCRIPTION", escape(todoItem.Description))
+		}
+		if todoItem.IsDone {
+			line("STATUS", "COMPLETED")
+			if !todoItem.CompletedAt.IsZero() {
+				line("COMPLETED", todoItem.CompletedAt.UTC().Format(utcLayout))
+			}
+		} else {
+			line("STATUS", "NEEDS-ACTION")
+		}
+		if todoItem.HasDue() {
+			if todoItem.DueDateOnly() {
+				line("DUE;VALUE=DATE", todoItem.DueAt.Local().Format(dateLayout))
+			} else {
+				line("DUE", todoItem.DueAt.UTC().Format(utcLayout))
+			}
+		}
+		line("CREATED", todoItem.CreatedAt.UTC().Format(utcLayout))
+		line("LAST-MODIFIED", todoItem.UpdatedAt.UTC().Format(utcLayout))
+		if todoItem.Priority != todo.PriorityNone {
+			line("PRIORITY", strconv.Itoa(formatPriority(todoItem.Priority)))
+		}
+		if len(todoItem.Tags) > 0 {
+			tags := make([]string, len(todoItem.Tags))
+			for i, tag := range todoItem.Tags {
+				tags[i] = escape(tag)
+			}
+			line("CATEGORIES", strings.Join(tags, ","))
+		}
+		if todoItem.Recurrence != todo.RecurrenceNone {
+			line("RRULE", todoItem.Recurrence.RRule())
+		}
+		if todoItem.Project != "" {
+			line("X-PROJECT", escape(todoItem.Project))
+		}
+		if todoItem.ParentID != "" {
+			line("RELATED-TO;RELTYPE=PARENT", todoItem.ParentID)
+		}
+		for _, dependency := range todoItem.Dependencies {
+			line("RELATED-TO;RELTYPE=DEPENDS-ON", dependency)
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+	return writer.Flush()
+}
+
+// writeLine writes a content line ending in CRLF, folding it so no line is longer than lineLength octets
+// without splitting a UTF-8 sequence.
+func writeLine(w *bufio.Writer, s string) {
+	limit := lineLength
+	for len(s) > limit {
+		i := limit
+		for i > 0 && s[i]&0xC0 == 0x80 {
+			i--
+		}
+		w.WriteString(s[:i])
+		w.WriteString("\r\n ")
+		s = s[i:]
+		// The space starting a continuation line counts towards its length.
+		limit = lineLength - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescape reverses escape.
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == 'n' || s[i] == 'N' {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitList splits a list of TEXT values on the commas that are not escaped, and unescapes them.
+func splitList(s string) []string {
+	values := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescape(s[start:]))
+}
+
+// formatPriority maps a priority to the PRIORITY values of RFC 5545, where 1 is the highest, 5 medium and 9
+// the lowest.
+func formatPriority(priority todo.Priority) int {
+	switch priority {
+	case "A":
+		return 1
+	case "B":
+		return 5
+	default:
+		return 9
+	}
+}
+
+// parsePriority reverses formatPriority, mapping the high values 1 to 4 to A, 5 to B and the low values to C.
+func parsePriority(value string) (todo.Priority, error) {
+	n, err := strconv.Atoi(value)
+	switch {
+	case err != nil || n < 0 || n > 9:
+		return todo.PriorityNone, fmt.Errorf("%w: Expected PRIORITY from 0 to 9, got %s", ErrInvalidCalendar, value)
+	case n == 0:
+		return todo.PriorityNone, nil
+	case n < 5:
+		return "A", nil
+	case n == 5:
+		return "B", nil
+	default:
+		return "C", nil
+	}
+}
+
+// property is a content line of an iCalendar file, split into its name, parameters and value.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseProperty parses an unfolded content line.
+func parseProperty(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+	// The value starts after the first colon outside quoted parameter values.
+	quoted := false
+	colon := -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return prop, fmt.Errorf("%w: Expected a property such as NAME:value, got %s", ErrInvalidCalendar, line)
+	}
+	prop.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+// parseTime parses a DATE or DATE-TIME value. Dates and floating times are in the local time zone, and times
+// with a TZID in that zone, or the local one if it is unknown.
+func parseTime(prop property) (time.Time, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: Expected %s as a date such as 20261020, got %s", ErrInvalidCalendar, prop.name, value)
+		}
+		return t, nil
+	}
+	location := time.Local
+	if tzid, ok := prop.params["TZID"]; ok {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+	layout := dateTimeLayout
+	if strings.HasSuffix(value, "Z") {
+		layout, location = utcLayout, time.UTC
+	}
+	t, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: Expected %s as a date and time such as 20261020T170000Z, got %s", ErrInvalidCalendar, prop.name, value)
+	}
+	return t, nil
+}
+
+// todoID returns the ID of the todo for a UID. UIDs written by Encode are todo IDs, and other UIDs, such as
+// those of calendar apps, are turned into one.
+func todoID(uid string) string {
+	if todoIDPattern.MatchString(uid) {
+		return uid
+	}
+	return todo.DerivedID(uid)
+}
+
+// Decode reads the VTODO components of an iCalendar file as todos, reversing Encode. Components of other
+// types, such as events, are skipped. Todos without CREATED are created at now. An RRULE the todos can't
+// repeat by, or a PRIORITY out of range, is dropped from its todo, and returned as a warning.
+func Decode(r io.Reader, now time.Time) ([]todo.TodoItem, []error, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todos := []todo.TodoItem{}
+	warnings := []error{}
+	var current *todo.TodoItem
+	var c component
+	for _, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, nil, err
+		}
+		if prop.name == "BEGIN" && strings.EqualFold(prop.value, "VTODO") {
+			current = &todo.TodoItem{}
+			c = component{}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if prop.name == "END" && strings.EqualFold(prop.value, "VTODO") {
+			if c.uid == "" {
+				return nil, nil, fmt.Errorf("%w: Missing UID in VTODO %s", ErrInvalidCalendar, current.Title)
+			}
+			for _, dropped := range c.dropped {
+				warnings = append(warnings, fmt.Errorf("%w of VTODO %s", dropped, c.uid))
+			}
+			finish(current, c, now)
+			todos = append(todos, *current)
+			current = nil
+			continue
+		}
+		if err := decodeProperty(current, prop, &c); err != nil {
+			return nil, nil, err
+		}
+	}
+	if current != nil {
+		return nil, nil, fmt.Errorf("%w: Missing END:VTODO", ErrInvalidCalendar)
+	}
+	return todos, warnings, nil
+}
+
+// unfold reads the content lines of an iCalendar file, joining folded lines.
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: Error reading data from iCalendar", err)
+	}
+	return lines, nil
+}
+
+// component holds the properties of the VTODO being decoded that are resolved once all of them are read, so
+// that the result doesn't depend on their order.
+type component struct {
+	uid    string
+	status string
+	// dropped holds the errors of the properties left out of the todo.
+	dropped []error
+}
+
+// drop records that the property was left out of the todo because of err.
+func (c *component) drop(prop property, err error) {
+	c.dropped = append(c.dropped, fmt.Errorf("%w. Dropped %s", err, prop.name))
+}
+
+// decodeProperty sets the field of the todo a property of its VTODO maps to, or records it in the component.
+// Other properties are ignored, and an unsupported PRIORITY or RRULE is dropped.
+func decodeProperty(todoItem *todo.TodoItem, prop property, c *component) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		c.uid = prop.value
+		todoItem.ID = todoID(prop.value)
+	case "SUMMARY":
+		todoItem.Title = unescape(prop.value)
+	case "DESCRIPTION":
+		todoItem.Description = unescape(prop.value)
+	case "STATUS":
+		c.status = strings.ToUpper(prop.value)
+	case "COMPLETED":
+		todoItem.CompletedAt, err = parseTime(prop)
+	case "DUE":
+		todoItem.DueAt, err = parseTime(prop)
+	case "CREATED":
+		todoItem.CreatedAt, err = parseTime(prop)
+	case "LAST-MODIFIED":
+		todoItem.UpdatedAt, err = parseTime(prop)
+	case "PRIORITY":
+		if priority, err := parsePriority(prop.value); err != nil {
+			c.drop(prop, err)
+		} else {
+			todoItem.Priority = priority
+		}
+	case "CATEGORIES":
+		for _, tag := range splitList(prop.value) {
+			if tag = strings.Join(strings.Fields(tag), "-"); tag != "" {
+				todoItem.AddTag(tag)
+			}
+		}
+	case "RRULE":
+		if recurrence, err := todo.ParseRecurrence(prop.value); err != nil {
+			c.drop(prop, err)
+		} else {
+			todoItem.Recurrence = recurrence
+		}
+	case "X-PROJECT":
+		todoItem.Project = unescape(prop.value)
+	case "RELATED-TO":
+		switch strings.ToUpper(prop.params["RELTYPE"]) {
+		case "", "PARENT":
+			todoItem.ParentID = todoID(prop.value)
+		case "DEPENDS-ON":
+			todoItem.Dependencies = append(todoItem.Dependencies, todoID(prop.value))
+		}
+	}
+	return err
+}
+
+// finish resolves whether the todo is done, from STATUS if the VTODO has one and else from COMPLETED, and fills
+// in the times it doesn't have.
+func finish(todoItem *todo.TodoItem, c component, now time.Time) {
+	if c.status != "" {
+		todoItem.IsDone = c.status == "COMPLETED"
+	} else {
+		todoItem.IsDone = !todoItem.CompletedAt.IsZero()
+	}
+	if todoItem.CreatedAt.IsZero() {
+		todoItem.CreatedAt = now
+	}
+	if todoItem.UpdatedAt.IsZero() {
+		todoItem.UpdatedAt = todoItem.CreatedAt
+	}
+	if !todoItem.IsDone {
+		todoItem.CompletedAt = time.Time{}
+	}
+}
+
+// Merge updates todoItem, the todo with the same ID as imported, with the fields a VTODO carries. The fields it
+// doesn't carry, such as the number, are kept.
+func Merge(todoItem *todo.TodoItem, imported todo.TodoItem) {
+	todoItem.Title = imported.Title
+	todoItem.Description = imported.Description
+	todoItem.IsDone = imported.IsDone
+	todoItem.CompletedAt = imported.CompletedAt
+	todoItem.DueAt = imported.DueAt
+	todoItem.CreatedAt = imported.CreatedAt
+	todoItem.UpdatedAt = imported.UpdatedAt
+	todoItem.Priority = imported.Priority
+	todoItem.Tags = imported.Tags
+	todoItem.Recurrence = imported.Recurrence
+	todoItem.Project = imported.Project
+	todoItem.ParentID = imported.ParentID
+	todoItem.Dependencies = imported.Dependencies
+}
@@ -0,0 +1,206 @@
+package ical
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	todos := []todo.TodoItem{
+		{
+			ID:           "3fa1c2d4e5b6a7f8",
+			ParentID:     "9c0d1e2f3a4b5c6d",
+			Title:        "Call mom, then dad; and the rest of the family about the trip to Lisbon next month",
+			Description:  "Ask about Sunday\nand bring cake \\o/",
+			IsDone:       true,
+			Priority:     "A",
+			DueAt:        time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local),
+			Recurrence:   "FREQ=DAILY;INTERVAL=3",
+			Project:      "family",
+			Tags:         []string{"phone", "home"},
+			Dependencies: []string{"a1b2c3d4e5f6a7b8"},
+			CreatedAt:    created,
+			UpdatedAt:    created.Add(48 * time.Hour),
+			CompletedAt:  created.Add(24 * time.Hour),
+		},
+		{
+			ID:        "9c0d1e2f3a4b5c6d",
+			Title:     "Plan trip",
+			Priority:  "B",
+			DueAt:     time.Date(2026, 10, 21, 17, 0, 0, 0, time.UTC),
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, todos, now); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	for _, want := range []string{
+		"BEGIN:VTODO\r\nUID:3fa1c2d4e5b6a7f8\r\nDTSTAMP:20261018T120000Z\r\n",
+		"SUMMARY:Call mom\\, then dad\\; and the rest of the family about the trip to \r\n Lisbon next month\r\n",
+		"DESCRIPTION:Ask about Sunday\\nand bring cake \\\\o/\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20261002T093000Z\r\nDUE;VALUE=DATE:20261020\r\n",
+		"CREATED:20261001T093000Z\r\nLAST-MODIFIED:20261003T093000Z\r\nPRIORITY:1\r\n",
+		"CATEGORIES:phone,home\r\nRRULE:FREQ=DAILY;INTERVAL=3\r\nX-PROJECT:family\r\nRELATED-TO;RELTYPE=PARENT:9c0d1e2f3a4b5c6d\r\n",
+		"RELATED-TO;RELTYPE=DEPENDS-ON:a1b2c3d4e5f6a7b8\r\n",
+		"STATUS:NEEDS-ACTION\r\nDUE:20261021T170000Z\r\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in %s", want, buf.String())
+		}
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > lineLength {
+			t.Errorf("Expected at most %d octets, got %d in %s", lineLength, len(line), line)
+		}
+	}
+
+	decoded, warnings, err := Decode(&buf, now)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(decoded) != len(todos) {
+		t.Fatalf("Expected %d todos, got %d", len(todos), len(decoded))
+	}
+	for i := range todos {
+		want, _ := json.Marshal(todos[i])
+		got, _ := json.Marshal(decoded[i])
+		if string(got) != string(want) {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:event-1@example.com",
+		"SUMMARY:Dentist",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:task-1@example.com",
+		"SUMMARY:Renew pass",
+		"DESCRIPTION:Bring two ",
+		" photos",
+		"DUE;TZID=Europe/Paris:20261021T170000",
+		"PRIORITY:3",
+		"CATEGORIES:Errands,Paper work",
+		"X-APPLE-SORT-ORDER:1",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\n")
+
+	todos, _, err := Decode(strings.NewReader(calendar), now)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todos) != 1 {
+		t.Fatalf("Expected 1 todo, got %d", len(todos))
+	}
+	got := todos[0]
+	if got.ID != todo.DerivedID("task-1@example.com") || got.Title != "Renew pass" || got.Description != "Bring two photos" {
+		t.Errorf("Expected Renew pass with a derived ID, got %+v", got)
+	}
+	paris, _ := time.LoadLocation("Europe/Paris")
+	if want := time.Date(2026, 10, 21, 17, 0, 0, 0, paris); !got.DueAt.Equal(want) {
+		t.Errorf("Expected %s, got %s", want, got.DueAt)
+	}
+	if got.Priority != "A" || strings.Join(got.Tags, " ") != "Errands Paper-work" {
+		t.Errorf("Expected priority A and 2 tags, got %s %v", got.Priority, got.Tags)
+	}
+	if !got.CreatedAt.Equal(now) || !got.UpdatedAt.Equal(now) || got.IsDone {
+		t.Errorf("Expected an incomplete todo created at %s, got %+v", now, got)
+	}
+
+	// Whether a todo is done doesn't depend on the order of STATUS and COMPLETED.
+	for _, tt := range []struct {
+		properties string
+		done       bool
+	}{
+		{"STATUS:NEEDS-ACTION\nCOMPLETED:20261002T093000Z", false},
+		{"COMPLETED:20261002T093000Z\nSTATUS:NEEDS-ACTION", false},
+		{"STATUS:COMPLETED\nCOMPLETED:20261002T093000Z", true},
+		{"COMPLETED:20261002T093000Z\nSTATUS:COMPLETED", true},
+		{"COMPLETED:20261002T093000Z", true},
+	} {
+		todos, _, err := Decode(strings.NewReader("BEGIN:VTODO\nUID:1\n"+tt.properties+"\nEND:VTODO"), now)
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if todos[0].IsDone != tt.done || todos[0].CompletedAt.IsZero() == tt.done {
+			t.Errorf("Expected done %t for %q, got %+v", tt.done, tt.properties, todos[0])
+		}
+	}
+
+	for _, calendar := range []string{
+		"BEGIN:VTODO\nSUMMARY:No UID\nEND:VTODO",
+		"BEGIN:VTODO\nUID:1\nDUE:tomorrow\nEND:VTODO",
+		"BEGIN:VTODO\nUID:1\nSUMMARY:Unfinished",
+		"BEGIN:VTODO\nUID:1\nNo colon\nEND:VTODO",
+	} {
+		if _, _, err := Decode(strings.NewReader(calendar), now); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("Expected %v for %q, got %v", ErrInvalidCalendar, calendar, err)
+		}
+	}
+	// Properties the todos can't hold are dropped from their todo, instead of failing the file.
+	calendar = strings.Join([]string{
+		"BEGIN:VTODO",
+		"UID:1",
+		"SUMMARY:Pay rent",
+		"RRULE:FREQ=MONTHLY;COUNT=12",
+		"PRIORITY:10",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:2",
+		"SUMMARY:Renew pass",
+		"RRULE:FREQ=YEARLY",
+		"PRIORITY:high",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:3",
+		"SUMMARY:Water plants",
+		"RRULE:FREQ=WEEKLY",
+		"PRIORITY:1",
+		"END:VTODO",
+	}, "\n")
+	todos, warnings, err := Decode(strings.NewReader(calendar), now)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todos) != 3 {
+		t.Fatalf("Expected 3 todos, got %d", len(todos))
+	}
+	for _, got := range todos[:2] {
+		if got.Recurrence != "" || got.Priority != todo.PriorityNone {
+			t.Errorf("Expected no recurrence and no priority, got %+v", got)
+		}
+	}
+	if todos[2].Recurrence == "" || todos[2].Priority != "A" {
+		t.Errorf("Expected a weekly todo of priority A, got %+v", todos[2])
+	}
+	if len(warnings) != 4 {
+		t.Fatalf("Expected 4 warnings, got %v", warnings)
+	}
+	for _, want := range []string{"Dropped RRULE of VTODO 1", "Dropped PRIORITY of VTODO 1", "Dropped RRULE of VTODO 2", "Dropped PRIORITY of VTODO 2"} {
+		found := false
+		for _, warning := range warnings {
+			found = found || strings.HasSuffix(warning.Error(), want)
+		}
+		if !found {
+			t.Errorf("Expected %q, got %v", want, warnings)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package markdown translates todo items to and from Markdown checklists, such as those of planning documents.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// checklistItem matches a checklist item such as `- [x] Call mom`, capturing its indentation, mark and title.
+var checklistItem = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// heading matches a heading such as `## +work`, capturing its text.
+var heading = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+
+// group is the todos sharing a project, or a first tag if they have no project, under a heading.
+type group struct {
+	heading string
+	todos   []todo.TodoItem
+}
+
+// Encode writes the todos as a Markdown checklist, `- [ ] title` for incomplete todos and `- [x] title` for done
+// ones. Todos are grouped under a `## +project` heading, or an `## @tag` heading for their first tag if they
+// have no project, after the todos that have neither. Subtasks are nested below their parent, in its group,
+// descriptions are indented below their todo, and the tags not named by the heading follow the title as `@tag`.
+func Encode(w io.Writer, todos []todo.TodoItem) error {
+	byID := make(map[string]todo.TodoItem, len(todos))
+	for _, todoItem := range todos {
+		byID[todoItem.ID] = todoItem
+	}
+	groups := []*group{{}}
+	byHeading := map[string]*group{"": groups[0]}
+	for _, todoItem := range todos {
+		root := todoItem
+		visited := map[string]bool{root.ID: true}
+		for !visited[root.ParentID] {
+			parent, ok := byID[root.ParentID]
+			if !ok {
+				break
+			}
+			visited[parent.ID] = true
+			root = parent
+		}
+		name := ""
+		if root.Project != "" {
+			name = "+" + root.Project
+		} else if len(root.Tags) > 0 {
+			name = "@" + root.Tags[0]
+		}
+		g, ok := byHeading[name]
+		if !ok {
+			g = &group{heading: name}
+			byHeading[name] = g
+			groups = append(groups, g)
+		}
+		g.todos = append(g.todos, todoItem)
+	}
+
+	writer := bufio.NewWriter(w)
+	first := true
+	for _, g := range groups {
+		if len(g.todos) == 0 {
+			continue
+		}
+		if !first {
+			writer.WriteString("\n")
+		}
+		first = false
+		if g.heading != "" {
+			fmt.Fprintf(writer, "## %s\n\n", g.heading)
+		}
+
+		ordered, depths := todo.Tree(g.todos)
+		for _, todoItem := range ordered {
+			indent := strings.Repeat("  ", depths[todoItem.ID])
+			mark := " "
+			if todoItem.IsDone {
+				mark = "x"
+			}
+			title := todoItem.Title
+			for _, tag := range todoItem.Tags {
+				if "@"+tag != g.heading {
+					title += " @" + tag
+				}
+			}
+			fmt.Fprintf(writer, "%s- [%s] %s\n", indent, mark, title)
+			if todoItem.Description == "" {
+				continue
+			}
+			for _, line := range strings.Split(todoItem.Description, "\n") {
+				if strings.TrimSpace(line) == "" {
+					writer.WriteString("\n")
+				} else {
+					fmt.Fprintf(writer, "%s  %s\n", indent, line)
+				}
+			}
+		}
+	}
+	return writer.Flush()
+}
+
+// entry is a checklist item being decoded, with the indentation of its line.
+type entry struct {
+	indent      int
+	todo        *todo.TodoItem
+	description []string
+}
+
+// Decode reads the checklist items of a Markdown document as new todos, reversing Encode. Items nested below
+// another are its subtasks, and text indented below an item is its description. `+project` and `@tag` in a
+// heading apply to the items under it, and in a title to that item. Items left without a title, such as
+// `- [ ] @tag`, are skipped with their description, and their subtasks take their place. Other lines are
+// skipped.
+func Decode(r io.Reader) ([]todo.TodoItem, error) {
+	entries := []*entry{}
+	// parents holds the items that can have the following item as a subtask, the most indented last.
+	parents := []*entry{}
+	var last *entry
+	project, tag := "", ""
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.ReplaceAll(scanner.Text(), "\t", "    ")
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if match := checklistItem.FindStringSubmatch(line); match != nil {
+			for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+				parents = parents[:len(parents)-1]
+			}
+			todoItem, err := decodeTitle(match[3], project, tag)
+			if err != nil {
+				return nil, err
+			}
+			if todoItem.Title == "" {
+				last = nil
+				continue
+			}
+			if match[2] != " " {
+				todoItem.Done()
+			}
+			if len(parents) > 0 {
+				todoItem.ParentID = parents[len(parents)-1].todo.ID
+			}
+			last = &entry{indent: indent, todo: todoItem}
+			entries = append(entries, last)
+			parents = append(parents, last)
+			continue
+		}
+		if match := heading.FindStringSubmatch(line); match != nil {
+			project, tag, last, parents = "", "", nil, nil
+			if text := match[1]; !strings.ContainsAny(text, " \t") && len(text) > 1 {
+				switch text[0] {
+				case '+':
+					project = text[1:]
+				case '@':
+					tag = text[1:]
+				}
+			}
+			continue
+		}
+		if last == nil {
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line) == "":
+			last.description = append(last.description, "")
+		case indent > last.indent:
+			last.description = append(last.description, line)
+		default:
+			last, parents = nil, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: Error reading data from Markdown", err)
+	}
+
+	todos := make([]todo.TodoItem, len(entries))
+	for i, e := range entries {
+		e.todo.Description = dedent(e.description)
+		todos[i] = *e.todo
+	}
+	return todos, nil
+}
+
+// dedent joins the lines of a description, removing the indentation they share and the blank lines around them.
+func dedent(lines []string) string {
+	shared := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if indent := len(line) - len(strings.TrimLeft(line, " ")); shared < 0 || indent < shared {
+			shared = indent
+		}
+	}
+	if shared < 0 {
+		return ""
+	}
+	dedented := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) > shared {
+			dedented[i] = line[shared:]
+		}
+	}
+	return strings.Trim(strings.Join(dedented, "\n"), "\n")
+}
+
+// decodeTitle returns a new todo with the title, moving its `+project` and `@tag` tokens to the project and the
+// tags as todo.ParseTitle does. The project and tag of the heading apply unless the title names a project.
+func decodeTitle(title, project, tag string) (*todo.TodoItem, error) {
+	parsed := todo.ParseTitle(title)
+	todoItem, err := todo.NewTodoItem(parsed.Title, "")
+	if err != nil {
+		return nil, err
+	}
+	todoItem.Project = project
+	if parsed.Project != "" {
+		todoItem.Project = parsed.Project
+	}
+	if tag != "" {
+		todoItem.AddTag(tag)
+	}
+	for _, t := range parsed.Tags {
+		todoItem.AddTag(t)
+	}
+	return todoItem, nil
+}
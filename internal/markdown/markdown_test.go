@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+func TestEncode(t *testing.T) {
+	todos := []todo.TodoItem{
+		{ID: "p1", Title: "Plan party", Project: "home", Tags: []string{"weekend"}},
+		{ID: "m2", Title: "Buy milk", IsDone: true, Description: "Whole milk\n\nNot skimmed"},
+		{ID: "v3", Title: "Book venue", ParentID: "p1", IsDone: true},
+		{ID: "c4", Title: "Call mom", Tags: []string{"phone", "family"}},
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, todos); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	want := strings.Join([]string{
+		"- [x] Buy milk",
+		"  Whole milk",
+		"",
+		"  Not skimmed",
+		"",
+		"## +home",
+		"",
+		"- [ ] Plan party @weekend",
+		"  - [x] Book venue",
+		"",
+		"## @phone",
+		"",
+		"- [ ] Call mom @family",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("Expected %s, got %s", want, buf.String())
+	}
+
+	// Decoding the checklist gives back the todos, with new IDs.
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(decoded) != len(todos) {
+		t.Fatalf("Expected %d todos, got %d", len(todos), len(decoded))
+	}
+	if got := decoded[0]; got.Title != "Buy milk" || !got.IsDone || got.Description != "Whole milk\n\nNot skimmed" {
+		t.Errorf("Expected Buy milk, got %+v", got)
+	}
+	if got := decoded[2]; got.Title != "Book venue" || got.Project != "home" || got.ParentID != decoded[1].ID {
+		t.Errorf("Expected Book venue below Plan party, got %+v", got)
+	}
+	if got := decoded[3]; got.Project != "" || strings.Join(got.Tags, " ") != "phone family" {
+		t.Errorf("Expected Call mom @phone @family, got %+v", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	document := strings.Join([]string{
+		"# Launch plan",
+		"",
+		"Some notes about the launch.",
+		"",
+		"- [ ] Write announcement +marketing @blog",
+		"    Keep it short,",
+		"    with a link to the docs.",
+		"    * [X] Draft",
+		"    * [ ] Review @team",
+		"- plain bullet",
+		"  - [ ] Not nested below a plain bullet",
+		"",
+		"## @ops",
+		"",
+		"1. ordered",
+		"- [x] Deploy",
+		"\t- [ ] Smoke test",
+		"- [ ] @urgent",
+		"    Skipped with its item.",
+		"    - [ ] Restart",
+		"- [ ] ",
+	}, "\n")
+
+	todos, err := Decode(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	want := []struct {
+		title       string
+		done        bool
+		parent      int
+		project     string
+		tags        string
+		description string
+	}{
+		{"Write announcement", false, -1, "marketing", "blog", "Keep it short,\nwith a link to the docs."},
+		{"Draft", true, 0, "", "", ""},
+		{"Review", false, 0, "", "team", ""},
+		{"Not nested below a plain bullet", false, -1, "", "", ""},
+		{"Deploy", true, -1, "", "ops", ""},
+		{"Smoke test", false, 4, "", "ops", ""},
+		{"Restart", false, -1, "", "ops", ""},
+	}
+	if len(todos) != len(want) {
+		t.Fatalf("Expected %d todos, got %d: %+v", len(want), len(todos), todos)
+	}
+	for i, w := range want {
+		got := todos[i]
+		parent := ""
+		if w.parent >= 0 {
+			parent = todos[w.parent].ID
+		}
+		if got.Title != w.title || got.IsDone != w.done || got.ParentID != parent || got.Project != w.project ||
+			strings.Join(got.Tags, " ") != w.tags || got.Description != w.description {
+			t.Errorf("Expected %+v, got %+v", w, got)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// Fields are the columns written by DelimitedRenderer, named after the TodoItem JSON tags.
+var Fields = []string{
+	"id",
+	"num",
+	"parent_id",
+	"title",
+	"description",
+	"is_done",
+	"priority",
+	"due_at",
+	"recurrence",
+	"project",
+	"tags",
+	"depends_on",
+	"created_at",
+	"updated_at",
+	"completed_at",
+	"deleted_at",
+}
+
+// DelimitedRenderer renders todo items as delimited text such as CSV or TSV, with a header row of Fields.
+// Timestamps are RFC 3339, unset ones are empty, and tags are separated by commas.
+type DelimitedRenderer struct {
+	Comma rune
+}
+
+func (r *DelimitedRenderer) Render(w io.Writer, todos []todo.TodoItem) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = r.Comma
+	if err := writer.Write(Fields); err != nil {
+		return err
+	}
+	for i := range todos {
+		record := make([]string, len(Fields))
+		for j, field := range Fields {
+			record[j] = fieldValue(&todos[i], field)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// fieldValue formats the field of a todo item named by its JSON tag.
+func fieldValue(todoItem *todo.TodoItem, field string) string {
+	switch field {
+	case "id":
+		return todoItem.ID
+	case "num":
+		return strconv.Itoa(todoItem.Num)
+	case "parent_id":
+		return todoItem.ParentID
+	case "title":
+		return todoItem.Title
+	case "description":
+		return todoItem.Description
+	case "is_done":
+		return strconv.FormatBool(todoItem.IsDone)
+	case "priority":
+		return string(todoItem.Priority)
+	case "due_at":
+		return formatTimestamp(todoItem.DueAt)
+	case "recurrence":
+		return string(todoItem.Recurrence)
+	case "project":
+		return todoItem.Project
+	case "tags":
+		return strings.Join(todoItem.Tags, ",")
+	case "depends_on":
+		return strings.Join(todoItem.Dependencies, ",")
+	case "created_at":
+		return formatTimestamp(todoItem.CreatedAt)
+	case "updated_at":
+		return formatTimestamp(todoItem.UpdatedAt)
+	case "completed_at":
+		return formatTimestamp(todoItem.CompletedAt)
+	case "deleted_at":
+		return formatTimestamp(todoItem.DeletedAt)
+	}
+	return ""
+}
+
+// formatTimestamp formats a timestamp as RFC 3339, or empty if it is unset.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// jsonItem is a todo item as rendered to JSON, with the times that are unset as null.
+type jsonItem struct {
+	*todo.TodoItem
+	DueAt       *time.Time `json:"due_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+// newJSONItem returns the todo item as rendered to JSON.
+func newJSONItem(todoItem *todo.TodoItem) jsonItem {
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	return jsonItem{
+		TodoItem:    todoItem,
+		DueAt:       optional(todoItem.DueAt),
+		CompletedAt: optional(todoItem.CompletedAt),
+		DeletedAt:   optional(todoItem.DeletedAt),
+	}
+}
+
+// JSONRenderer renders todo items as a JSON array, with the field names of the TodoItem JSON tags. Unset
+// times are null.
+type JSONRenderer struct{}
+
+func (r *JSONRenderer) Render(w io.Writer, todos []todo.TodoItem) error {
+	items := make([]jsonItem, len(todos))
+	for i := range todos {
+		items[i] = newJSONItem(&todos[i])
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+// NDJSONRenderer renders todo items as newline delimited JSON, one object per line, like JSONRenderer.
+type NDJSONRenderer struct{}
+
+func (r *NDJSONRenderer) Render(w io.Writer, todos []todo.TodoItem) error {
+	encoder := json.NewEncoder(w)
+	for i := range todos {
+		if err := encoder.Encode(newJSONItem(&todos[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
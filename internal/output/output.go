@@ -0,0 +1,106 @@
+// Package output renders lists of todo items in the formats supported by the list action.
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// Formats accepted by NewRenderer.
+const (
+	FormatTable  = "table"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+)
+
+// Formats lists the formats accepted by NewRenderer.
+var Formats = []string{FormatTable, FormatJSON, FormatNDJSON, FormatCSV, FormatTSV}
+
+// Renderer writes a list of todo items to w.
+type Renderer interface {
+	Render(w io.Writer, todos []todo.TodoItem) error
+}
+
+// Options configures how renderers display todo items.
+type Options struct {
+	// Color enables ANSI colors in human readable formats.
+	Color bool
+	// Now is the time due dates are compared against.
+	Now time.Time
+	// ShortIDs maps IDs to the unambiguous prefixes shown in human readable formats. IDs missing from it are
+	// shown in full.
+	ShortIDs map[string]string
+	// Depths maps IDs to how deep subtasks are in the tree of todo items, and Progress the IDs of todo items
+	// with subtasks to how many of them are done. Missing IDs are at the top level, without subtasks.
+	Depths   map[string]int
+	Progress map[string]todo.Progress
+	// Blocked holds the IDs of the todo items waiting for others to be done.
+	Blocked map[string]bool
+}
+
+// NewRenderer returns the renderer for the format.
+func NewRenderer(format string, options Options) (Renderer, error) {
+	switch format {
+	case FormatTable, "":
+		return newTemplateRenderer(tableHeader, tableRow, options)
+	case FormatJSON:
+		return &JSONRenderer{}, nil
+	case FormatNDJSON:
+		return &NDJSONRenderer{}, nil
+	case FormatCSV:
+		return &DelimitedRenderer{Comma: ','}, nil
+	case FormatTSV:
+		return &DelimitedRenderer{Comma: '\t'}, nil
+	default:
+		return nil, fmt.Errorf("Expected format as one of %s, got %s", strings.Join(Formats, ", "), format)
+	}
+}
+
+// ANSI colors for table rows. They all have the same length, so rows stay aligned by tabwriter.
+const (
+	ColorDefault = "\x1b[39m"
+	ColorRed     = "\x1b[31m"
+	ColorYellow  = "\x1b[33m"
+	ColorReset   = "\x1b[0m"
+	// ColorHighlight marks matched search terms.
+	ColorHighlight = "\x1b[1;36m"
+)
+
+// UseColor reports whether output to the file should be colored: it must be a terminal and NO_COLOR unset.
+func UseColor(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// FormatTitle formats the title of a todo item followed by its +project and @tag tokens.
+func FormatTitle(todoItem todo.TodoItem) string {
+	title := todoItem.Title
+	if todoItem.Project != "" {
+		title += " +" + todoItem.Project
+	}
+	for _, tag := range todoItem.Tags {
+		title += " @" + tag
+	}
+	return title
+}
+
+// FormatDue formats the due date of a todo item, leaving out the time of day for date-only due dates.
+func FormatDue(todoItem todo.TodoItem) string {
+	if !todoItem.HasDue() {
+		return ""
+	}
+	if todoItem.DueDateOnly() {
+		return todoItem.DueAt.Local().Format("2006-01-02")
+	}
+	return todoItem.DueAt.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,248 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+func makeTodos() []todo.TodoItem {
+	created := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	return []todo.TodoItem{
+		{
+			ID:        "a1",
+			Num:       1,
+			Title:     "Deploy app",
+			Priority:  "A",
+			DueAt:     time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+			Project:   "work",
+			Tags:      []string{"ops", "urgent"},
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		{
+			ID:          "b2",
+			Num:         2,
+			Title:       "Buy milk",
+			Description: "Semi-skimmed, 2 pints",
+			IsDone:      true,
+			CreatedAt:   created,
+			UpdatedAt:   created,
+		},
+	}
+}
+
+// jsonFields returns the JSON tags of the exported TodoItem fields.
+func jsonFields() []string {
+	fields := []string{}
+	typ := reflect.TypeOf(todo.TodoItem{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if typ.Field(i).IsExported() && tag != "" && tag != "-" {
+			fields = append(fields, tag)
+		}
+	}
+	return fields
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range append(Formats, "") {
+		if _, err := NewRenderer(format, Options{}); err != nil {
+			t.Errorf("Expected nil, got `%s`", err)
+		}
+	}
+	if _, err := NewRenderer("xml", Options{}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JSONRenderer{}).Render(&buf, makeTodos()); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	var items []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected %d, got %d", 2, len(items))
+	}
+	for _, field := range jsonFields() {
+		if _, ok := items[0][field]; !ok {
+			t.Errorf("Expected field %s, got %v", field, items[0])
+		}
+	}
+	// Unset times are null, as they are empty in CSV.
+	if items[0]["due_at"] != "2026-10-15T00:00:00Z" || items[1]["due_at"] != nil || items[1]["completed_at"] != nil || items[1]["deleted_at"] != nil {
+		t.Errorf("Expected a due date for a1 and null times for b2, got %v and %v", items[0], items[1])
+	}
+
+	buf.Reset()
+	if err := (&JSONRenderer{}).Render(&buf, nil); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected %s, got %s", "[]", buf.String())
+	}
+}
+
+func TestNDJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&NDJSONRenderer{}).Render(&buf, makeTodos()); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected %d, got %d", 2, len(lines))
+	}
+	for i, want := range []string{"a1", "b2"} {
+		var item todo.TodoItem
+		if err := json.Unmarshal([]byte(lines[i]), &item); err != nil {
+			t.Fatalf("Expected nil, got `%s`", err)
+		}
+		if item.ID != want {
+			t.Errorf("Expected %s, got %s", want, item.ID)
+		}
+	}
+	if !strings.Contains(lines[1], `"due_at":null`) {
+		t.Errorf("Expected %s, got %s", `"due_at":null`, lines[1])
+	}
+}
+
+func TestDelimitedRenderer(t *testing.T) {
+	// Every JSON field must have a column, so the formats don't drift apart.
+	for _, field := range jsonFields() {
+		found := false
+		for _, column := range Fields {
+			found = found || column == field
+		}
+		if !found {
+			t.Errorf("Expected column %s in %v", field, Fields)
+		}
+	}
+
+	for _, comma := range []rune{',', '\t'} {
+		var buf bytes.Buffer
+		if err := (&DelimitedRenderer{Comma: comma}).Render(&buf, makeTodos()); err != nil {
+			t.Fatalf("Expected nil, got `%s`", err)
+		}
+		reader := csv.NewReader(&buf)
+		reader.Comma = comma
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("Expected nil, got `%s`", err)
+		}
+		want := [][]string{
+			Fields,
+			{"a1", "1", "", "Deploy app", "", "false", "A", "2026-10-15T00:00:00Z", "", "work", "ops,urgent", "", "2026-10-14T09:30:00Z", "2026-10-14T09:30:00Z", "", ""},
+			{"b2", "2", "", "Buy milk", "Semi-skimmed, 2 pints", "true", "", "", "", "", "", "", "2026-10-14T09:30:00Z", "2026-10-14T09:30:00Z", "", ""},
+		}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("Expected %v, got %v", want, records)
+		}
+	}
+}
+
+func TestTableRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	renderer, _ := NewRenderer(FormatTable, Options{Now: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)})
+	if err := renderer.Render(&buf, makeTodos()); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Expected no colors, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Deploy app +work @ops @urgent") {
+		t.Errorf("Expected title with project and tags, got %s", buf.String())
+	}
+
+	// Subtasks are indented below their parent, which shows their progress.
+	buf.Reset()
+	renderer, _ = NewRenderer(FormatTable, Options{
+		Depths:   map[string]int{"b2": 1},
+		Progress: map[string]todo.Progress{"a1": {Done: 1, Total: 1}},
+		Blocked:  map[string]bool{"a1": true},
+	})
+	if err := renderer.Render(&buf, makeTodos()); err != nil {
+		t.Fatalf("Expected nil, got `%s`", err)
+	}
+	if !strings.Contains(buf.String(), "Deploy app +work @ops @urgent (1/1)") {
+		t.Errorf("Expected title with progress, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "    Buy milk") {
+		t.Errorf("Expected indented title, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "blocked") {
+		t.Errorf("Expected blocked item, got %s", buf.String())
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		color    bool
+		want     string
+	}{
+		{"Fields", "{{.ID}} {{.Title}} {{.IsDone}}", false, "a1 Deploy app false\nb2 Buy milk true\n"},
+		{"Short ID", "{{.ID | short}}", false, "a\nb2\n"},
+		{"Date", "{{date .CreatedAt}}", false, "2026-10-14\n2026-10-14\n"},
+		{"Truncate", "{{.Description | truncate 6}}", false, "\nSemi-…\n"},
+		{"Join", `{{join .Tags ","}}`, false, "ops,urgent\n\n"},
+		{"Aligned columns", "{{.ID}}\t{{.Title}}\t{{.Priority}}", false, "a1  Deploy app  A\nb2  Buy milk    -\n"},
+		{"Colors disabled", `{{color "red"}}{{.ID}}{{reset}}`, false, "a1\nb2\n"},
+		{"Colors enabled", `{{color "red"}}{{.ID}}{{reset}}`, true, "\x1b[31ma1\x1b[0m\n\x1b[31mb2\x1b[0m\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := NewTemplateRenderer(tt.template, Options{Color: tt.color, Now: now, ShortIDs: map[string]string{"a1": "a"}})
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			var buf bytes.Buffer
+			if err := renderer.Render(&buf, makeTodos()); err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+
+	if _, err := NewTemplateRenderer("{{.Title", Options{}); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+	renderer, _ := NewTemplateRenderer(`{{color "plaid"}}`, Options{})
+	if err := renderer.Render(&bytes.Buffer{}, makeTodos()); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
+
+func TestFormatRelative(t *testing.T) {
+	now := time.Date(2026, 10, 14, 23, 0, 0, 0, time.Local)
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{time.Time{}, ""},
+		{time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local), "today"},
+		{time.Date(2026, 10, 15, 1, 0, 0, 0, time.Local), "tomorrow"},
+		{time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local), "yesterday"},
+		{time.Date(2026, 11, 14, 0, 0, 0, 0, time.Local), "in 31 days"},
+		{time.Date(2026, 10, 10, 0, 0, 0, 0, time.Local), "4 days ago"},
+	}
+	for _, tt := range tests {
+		if got := FormatRelative(tt.date, now); got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// colors maps the color names accepted by the `color` template function to their ANSI escape sequences.
+// They all have the same length, so colored columns stay aligned by tabwriter.
+var colors = map[string]string{
+	"default": ColorDefault,
+	"red":     ColorRed,
+	"green":   "\x1b[32m",
+	"yellow":  ColorYellow,
+	"blue":    "\x1b[34m",
+	"magenta": "\x1b[35m",
+	"cyan":    "\x1b[36m",
+}
+
+// The table format is a template like any other.
+const (
+	tableHeader = "{{color \"default\"}}#\tID\tPri\tTitle\tDescription\tDone\tDue\tCreated At{{reset}}\n" +
+		"{{color \"default\"}}-\t--\t---\t-----\t-----------\t----\t---\t----------{{reset}}\n"
+	tableRow = "{{dueColor .}}{{.Num}}\t{{.ID | short}}\t{{.Priority}}\t{{indent .}}{{title .}}{{progress .}}\t{{.Description}}\t{{if blocked .}}blocked{{else}}{{.IsDone}}{{end}}\t{{due .}}\t" +
+		"{{.CreatedAt.Format \"2006-01-02 03:04:05 PM\"}}{{reset}}"
+)
+
+// TemplateRenderer renders each todo item with a text/template, one item per line. Tabs in the output align
+// columns, and an optional header is rendered once before the items.
+type TemplateRenderer struct {
+	header *template.Template
+	row    *template.Template
+}
+
+// NewTemplateRenderer parses the template rendering each todo item. Besides the text/template builtins, the
+// template can use:
+//   - short: the shortest unambiguous prefix of an ID, `{{.ID | short}}`
+//   - title: the title followed by +project and @tag tokens, `{{title .}}`
+//   - indent: two spaces for each level a subtask is below the top level, `{{indent .}}{{.Title}}`
+//   - progress: how many subtasks are done, such as ` (3/5)`, empty without subtasks, `{{.Title}}{{progress .}}`
+//   - blocked: whether the item waits for others to be done, `{{if blocked .}}blocked{{end}}`
+//   - due: the due date, without the time of day for date-only due dates, `{{due .}}`
+//   - date: a date as YYYY-MM-DD, `{{date .CreatedAt}}`
+//   - relative: a date relative to now, such as `tomorrow` or `3 days ago`, `{{.DueAt | relative}}`
+//   - truncate: text shortened to at most n characters, `{{.Title | truncate 20}}`
+//   - join: elements joined by a separator, `{{join .Tags ","}}`
+//   - color, dueColor and reset: colors by name, by how soon the item is due, and back to the default
+func NewTemplateRenderer(text string, options Options) (*TemplateRenderer, error) {
+	return newTemplateRenderer("", text, options)
+}
+
+func newTemplateRenderer(header, row string, options Options) (*TemplateRenderer, error) {
+	r := &TemplateRenderer{}
+	funcs := templateFuncs(options)
+	if header != "" {
+		t, err := template.New("header").Funcs(funcs).Parse(header)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing template: %w", err)
+		}
+		r.header = t
+	}
+	if !strings.HasSuffix(row, "\n") {
+		row += "\n"
+	}
+	t, err := template.New("row").Funcs(funcs).Parse(row)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing template: %w", err)
+	}
+	r.row = t
+	return r, nil
+}
+
+func (r *TemplateRenderer) Render(w io.Writer, todos []todo.TodoItem) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if r.header != nil {
+		if err := r.header.Execute(writer, nil); err != nil {
+			return err
+		}
+	}
+	for _, todoItem := range todos {
+		if err := r.row.Execute(writer, todoItem); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// templateFuncs returns the functions available to templates.
+func templateFuncs(options Options) template.FuncMap {
+	color := func(color string) string {
+		if !options.Color {
+			return ""
+		}
+		return color
+	}
+	return template.FuncMap{
+		"short": func(id string) string {
+			if short, ok := options.ShortIDs[id]; ok {
+				return short
+			}
+			return id
+		},
+		"title": FormatTitle,
+		"indent": func(todoItem todo.TodoItem) string {
+			return strings.Repeat("  ", options.Depths[todoItem.ID])
+		},
+		"progress": func(todoItem todo.TodoItem) string {
+			if progress, ok := options.Progress[todoItem.ID]; ok {
+				return fmt.Sprintf(" (%s)", progress)
+			}
+			return ""
+		},
+		"blocked": func(todoItem todo.TodoItem) bool {
+			return options.Blocked[todoItem.ID]
+		},
+		"due": FormatDue,
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Local().Format("2006-01-02")
+		},
+		"relative": func(t time.Time) string {
+			return FormatRelative(t, options.Now)
+		},
+		"truncate": Truncate,
+		"join":     strings.Join,
+		"color": func(name string) (string, error) {
+			code, ok := colors[name]
+			if !ok {
+				return "", fmt.Errorf("Unknown color %s", name)
+			}
+			return color(code), nil
+		},
+		"dueColor": func(todoItem todo.TodoItem) string {
+			if todoItem.IsOverdue(options.Now) {
+				return color(ColorRed)
+			}
+			if todoItem.IsDueToday(options.Now) {
+				return color(ColorYellow)
+			}
+			return color(ColorDefault)
+		},
+		"reset": func() string {
+			return color(ColorReset)
+		},
+	}
+}
+
+// FormatRelative formats a date relative to now in days, such as `today`, `in 3 days` or `yesterday`.
+func FormatRelative(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	// Count calendar days in UTC, where days are all 24 hours long.
+	t, now = t.Local(), now.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(day.Sub(today).Hours() / 24)
+	switch {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "yesterday"
+	case days > 1:
+		return fmt.Sprintf("in %d days", days)
+	default:
+		return fmt.Sprintf("%d days ago", -days)
+	}
+}
+
+// Truncate shortens s to at most n characters, ending it with an ellipsis if it was cut.
+func Truncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
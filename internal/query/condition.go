@@ -0,0 +1,229 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// Operators of a condition.
+const (
+	OpEqual        = ":"
+	OpContains     = "~"
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// operators are ordered so that two-character operators are found before their one-character prefixes.
+var operators = []string{OpLessEqual, OpGreaterEqual, OpEqual, OpContains, OpLess, OpGreater}
+
+// fieldAliases maps the field names accepted in queries to their canonical names.
+var fieldAliases = map[string]string{
+	"id":          "id",
+	"title":       "title",
+	"description": "description",
+	"desc":        "description",
+	"text":        "text",
+	"done":        "done",
+	"priority":    "priority",
+	"pri":         "priority",
+	"project":     "project",
+	"tag":         "tag",
+	"due":         "due",
+	"created":     "created",
+	"updated":     "updated",
+	"completed":   "completed",
+}
+
+// Condition matches todo items whose field compares to the value with the operator.
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+
+	// The value parsed for the field.
+	boolean  bool
+	priority todo.Priority
+	date     time.Time
+	// dateOnly is set when date has no time of day and should be compared by day.
+	dateOnly bool
+	// none is set for `field:none` on optional fields.
+	none bool
+}
+
+// parseTerm parses a single term such as `tag:work`, `+work` or `deploy`.
+func parseTerm(token string, now time.Time) (Node, error) {
+	if len(token) > 1 && token[0] == '+' {
+		return newCondition("project", OpEqual, token[1:], now)
+	}
+	if len(token) > 1 && token[0] == '@' {
+		return newCondition("tag", OpEqual, token[1:], now)
+	}
+
+	for i, r := range token {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			continue
+		}
+		// Words such as `10:30` or `http://example.com` don't name a field and are searched for as text.
+		if _, ok := fieldAliases[strings.ToLower(token[:i])]; !ok {
+			break
+		}
+		for _, op := range operators {
+			if strings.HasPrefix(token[i:], op) {
+				return newCondition(strings.ToLower(token[:i]), op, token[i+len(op):], now)
+			}
+		}
+		break
+	}
+
+	return newCondition("text", OpContains, token, now)
+}
+
+// newCondition validates the field and operator, and parses the value for the field.
+func newCondition(field, op, value string, now time.Time) (*Condition, error) {
+	name, ok := fieldAliases[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+	c := &Condition{Field: name, Op: op, Value: value}
+
+	invalidOp := func() (*Condition, error) {
+		return nil, fmt.Errorf("%w: %s does not support %s", ErrSyntax, field, op)
+	}
+	ordered := op == OpLess || op == OpLessEqual || op == OpGreater || op == OpGreaterEqual
+
+	switch name {
+	case "id", "title", "description", "text", "project", "tag":
+		if ordered {
+			return invalidOp()
+		}
+	case "done":
+		if op != OpEqual {
+			return invalidOp()
+		}
+		switch strings.ToLower(value) {
+		case "true", "yes":
+			c.boolean = true
+		case "false", "no":
+			c.boolean = false
+		default:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: Expected done as true or false, got %s", ErrSyntax, value)
+			}
+			c.boolean = b
+		}
+	case "priority":
+		if op == OpContains {
+			return invalidOp()
+		}
+		priority, err := todo.ParsePriority(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrSyntax, err)
+		}
+		c.priority = priority
+	case "due", "created", "updated", "completed":
+		if op == OpContains {
+			return invalidOp()
+		}
+		if strings.EqualFold(value, "none") {
+			if op != OpEqual {
+				return invalidOp()
+			}
+			c.none = true
+			break
+		}
+		date, err := todo.ParseDate(value, now)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrSyntax, err)
+		}
+		c.date = date
+		c.dateOnly = !strings.Contains(value, ":")
+	}
+
+	return c, nil
+}
+
+func (c *Condition) Match(todoItem *todo.TodoItem) bool {
+	switch c.Field {
+	case "id":
+		return strings.HasPrefix(todoItem.ID, c.Value)
+	case "title":
+		return c.matchText(todoItem.Title)
+	case "description":
+		return c.matchText(todoItem.Description)
+	case "text":
+		return c.matchText(todoItem.Title) || c.matchText(todoItem.Description)
+	case "project":
+		return c.matchText(todoItem.Project)
+	case "tag":
+		for _, tag := range todoItem.Tags {
+			if c.matchText(tag) {
+				return true
+			}
+		}
+		return false
+	case "done":
+		return todoItem.IsDone == c.boolean
+	case "priority":
+		return c.compare(importance(todoItem.Priority) - importance(c.priority))
+	case "due":
+		return c.matchDate(todoItem.DueAt)
+	case "created":
+		return c.matchDate(todoItem.CreatedAt)
+	case "updated":
+		return c.matchDate(todoItem.UpdatedAt)
+	case "completed":
+		return c.matchDate(todoItem.CompletedAt)
+	}
+	return false
+}
+
+// matchText matches a text field, ignoring case.
+func (c *Condition) matchText(s string) bool {
+	if c.Op == OpContains {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(c.Value))
+	}
+	return strings.EqualFold(s, c.Value)
+}
+
+// matchDate matches a date field. Unset dates only match `none`.
+func (c *Condition) matchDate(t time.Time) bool {
+	if c.none || t.IsZero() {
+		return c.none && t.IsZero()
+	}
+	if c.dateOnly {
+		t = t.In(c.date.Location())
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.date.Location())
+	}
+	return c.compare(t.Compare(c.date))
+}
+
+// compare applies the ordered operator to the result of comparing the field to the value.
+func (c *Condition) compare(cmp int) bool {
+	switch c.Op {
+	case OpLess:
+		return cmp < 0
+	case OpLessEqual:
+		return cmp <= 0
+	case OpGreater:
+		return cmp > 0
+	case OpGreaterEqual:
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// importance ranks priorities so that more important ones are greater, and PriorityNone is the least.
+func importance(priority todo.Priority) int {
+	if priority == todo.PriorityNone {
+		return 0
+	}
+	return int('Z'-priority[0]) + 1
+}
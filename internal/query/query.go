@@ -0,0 +1,242 @@
+// Package query implements the filter language of the list action, such as
+// `done:false tag:work due<fri title~deploy`.
+//
+// A query is a sequence of terms that must all match. `or` between terms matches either side, `-` or `not`
+// before a term negates it, and parentheses group terms. A term is one of:
+//
+//	field:value   the field equals the value, or has it for tags
+//	field~value   the field contains the value, ignoring case
+//	field<value   the field is less than the value, likewise for <=, > and >=
+//	+project      shorthand for project:project
+//	@tag          shorthand for tag:tag
+//	word          the title or description contains the word
+//
+// A term whose field is not one of the fields below, such as `10:30` or `http://example.com`, is a word.
+// The fields are id, title, description (desc), done, priority (pri), project, tag, due, created, updated and
+// completed.
+// Priorities compare by importance, so `priority>C` matches A and B. Dates accept the same values as
+// todo.ParseDate and compare by day unless a time of day is given. `due:none` matches items without a due
+// date, and likewise for the other dates.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+var (
+	ErrSyntax       = errors.New("Invalid query")
+	ErrUnknownField = errors.New("Unknown query field")
+)
+
+// Node is a node of the filter AST.
+type Node interface {
+	// Match reports whether the todo item matches the node.
+	Match(todo *todo.TodoItem) bool
+}
+
+// And matches todo items matching all of its nodes.
+type And struct {
+	Nodes []Node
+}
+
+// Or matches todo items matching any of its nodes.
+type Or struct {
+	Nodes []Node
+}
+
+// Not matches todo items not matching its node.
+type Not struct {
+	Node Node
+}
+
+func (n And) Match(todo *todo.TodoItem) bool {
+	for _, node := range n.Nodes {
+		if !node.Match(todo) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n Or) Match(todo *todo.TodoItem) bool {
+	for _, node := range n.Nodes {
+		if node.Match(todo) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Not) Match(todo *todo.TodoItem) bool {
+	return !n.Node.Match(todo)
+}
+
+// Filter returns the todo items matching the node, in their original order. A nil node matches every item.
+func Filter(todos []todo.TodoItem, node Node) []todo.TodoItem {
+	filtered := []todo.TodoItem{}
+	for i := range todos {
+		if node == nil || node.Match(&todos[i]) {
+			filtered = append(filtered, todos[i])
+		}
+	}
+	return filtered
+}
+
+// Parse parses the query terms into a filter AST, resolving relative dates against now. It returns nil if
+// there are no terms.
+func Parse(terms []string, now time.Time) (Node, error) {
+	tokens, err := tokenize(terms)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens, now: now}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: Unexpected %q", ErrSyntax, p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+// parser holds the state of the query parser.
+type parser struct {
+	tokens []string
+	pos    int
+	now    time.Time
+}
+
+// peek returns the current token, or an empty string at the end of the query.
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// parseOr parses `and (or and)*`.
+func (p *parser) parseOr() (Node, error) {
+	nodes := []Node{}
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !strings.EqualFold(p.peek(), "or") {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return Or{Nodes: nodes}, nil
+}
+
+// parseAnd parses one or more unary terms up to `or`, `)` or the end of the query.
+func (p *parser) parseAnd() (Node, error) {
+	nodes := []Node{}
+	for {
+		token := p.peek()
+		if token == "" || token == ")" || strings.EqualFold(token, "or") {
+			break
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: Expected a term, got %q", ErrSyntax, p.peek())
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return And{Nodes: nodes}, nil
+}
+
+// parseUnary parses `not unary`, `( or )` or a term.
+func (p *parser) parseUnary() (Node, error) {
+	token := p.peek()
+	switch {
+	case strings.EqualFold(token, "not"):
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node}, nil
+	case token == "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("%w: Expected )", ErrSyntax)
+		}
+		p.pos++
+		return node, nil
+	}
+
+	p.pos++
+	if len(token) > 1 && token[0] == '-' {
+		node, err := parseTerm(token[1:], p.now)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node}, nil
+	}
+	return parseTerm(token, p.now)
+}
+
+// tokenize splits the terms into tokens. Double quotes keep spaces inside a value, such as
+// `title~"deploy app"`, and parentheses are tokens of their own.
+func tokenize(terms []string) ([]string, error) {
+	tokens := []string{}
+	input := strings.Join(terms, " ")
+	var token strings.Builder
+	quoted := false
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+			token.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		case (r == '(' || r == ')') && token.Len() == 0:
+			tokens = append(tokens, string(r))
+		case r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			token.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: Unterminated quote", ErrSyntax)
+	}
+	flush()
+
+	return tokens, nil
+}
@@ -0,0 +1,146 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// Wednesday
+var now = time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local)
+
+func makeTodos() []todo.TodoItem {
+	return []todo.TodoItem{
+		{
+			ID:       "a1",
+			Num:      3,
+			Title:    "Deploy app",
+			IsDone:   false,
+			Priority: "A",
+			DueAt:    time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local),
+			Project:  "work",
+			Tags:     []string{"ops"},
+		},
+		{
+			ID:          "b2",
+			Num:         1,
+			Title:       "Buy milk",
+			Description: "Semi-skimmed",
+			IsDone:      true,
+			CompletedAt: time.Date(2026, 10, 13, 18, 0, 0, 0, time.Local),
+			Project:     "home",
+			Tags:        []string{"errand"},
+		},
+		{
+			ID:          "c3",
+			Num:         2,
+			Title:       "Write report",
+			Description: "Draft by 10:30, see http://wiki/report",
+			Priority:    "C",
+			DueAt:       time.Date(2026, 10, 20, 17, 0, 0, 0, time.Local),
+			Project:     "work",
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  []string
+	}{
+		{"Empty query matches all", []string{}, []string{"a1", "b2", "c3"}},
+		{"Done", []string{"done:false"}, []string{"a1", "c3"}},
+		{"Project shorthand", []string{"+work"}, []string{"a1", "c3"}},
+		{"Tag", []string{"tag:errand"}, []string{"b2"}},
+		{"Tag shorthand", []string{"@ops"}, []string{"a1"}},
+		{"Title contains", []string{"title~deploy"}, []string{"a1"}},
+		{"Text", []string{"skimmed"}, []string{"b2"}},
+		{"Due before friday", []string{"due<fri"}, []string{"a1"}},
+		{"Due on or before next tuesday", []string{"due<=+6d"}, []string{"a1", "c3"}},
+		{"No due date", []string{"due:none"}, []string{"b2"}},
+		{"Completed since yesterday", []string{"completed>=yesterday"}, []string{"b2"}},
+		{"Not completed", []string{"completed:none"}, []string{"a1", "c3"}},
+		{"Priority at least B", []string{"priority>=B"}, []string{"a1"}},
+		{"No priority", []string{"pri:none"}, []string{"b2"}},
+		{"Conjunction", []string{"done:false tag:ops due<fri title~deploy"}, []string{"a1"}},
+		{"Disjunction", []string{"tag:ops", "or", "tag:errand"}, []string{"a1", "b2"}},
+		{"Negation", []string{"-+work"}, []string{"b2"}},
+		{"Not", []string{"not", "done:true", "not", "@ops"}, []string{"c3"}},
+		{"Grouping", []string{"(+home or @ops)", "done:false"}, []string{"a1"}},
+		{"Quoted value", []string{`title~"write rep"`}, []string{"c3"}},
+		{"Text with a colon", []string{"10:30"}, []string{"c3"}},
+		{"Text with an unknown field", []string{"http://wiki"}, []string{"c3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input, now)
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			ids := []string{}
+			for _, todo := range Filter(makeTodos(), node) {
+				ids = append(ids, todo.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestParseFailures(t *testing.T) {
+	for _, input := range []string{
+		"done:maybe",
+		"title<b",
+		"due<someday",
+		"(tag:ops",
+		"tag:ops or",
+		`title~"open`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := Parse([]string{input}, now); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		keys string
+		want []string
+	}{
+		{"due", []string{"a1", "c3", "b2"}},
+		{"-due", []string{"c3", "a1", "b2"}},
+		{"-priority", []string{"a1", "c3", "b2"}},
+		{"project,-priority", []string{"b2", "a1", "c3"}},
+		{"title", []string{"b2", "a1", "c3"}},
+		{"num", []string{"b2", "c3", "a1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			keys, err := ParseSort(tt.keys)
+			if err != nil {
+				t.Fatalf("Expected nil, got `%s`", err)
+			}
+			todos := makeTodos()
+			Sort(todos, keys)
+			ids := []string{}
+			for _, todo := range todos {
+				ids = append(ids, todo.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	if _, err := ParseSort("due,colour"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+}
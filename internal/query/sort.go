@@ -0,0 +1,130 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// sortFields maps the field names accepted as sort keys to their canonical names.
+var sortFields = map[string]string{
+	"id":        "id",
+	"num":       "num",
+	"title":     "title",
+	"done":      "done",
+	"priority":  "priority",
+	"pri":       "priority",
+	"project":   "project",
+	"due":       "due",
+	"created":   "created",
+	"updated":   "updated",
+	"completed": "completed",
+}
+
+// SortKey is a field to sort by, in ascending order unless Descending is set.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSort parses comma separated sort keys such as `due,-priority`, where `-` sorts in descending order.
+// Priorities sort by importance, so `-priority` puts the most important items first.
+func ParseSort(s string) ([]SortKey, error) {
+	keys := []SortKey{}
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		key := SortKey{}
+		if strings.HasPrefix(field, "-") {
+			key.Descending = true
+			field = field[1:]
+		}
+		name, ok := sortFields[strings.ToLower(field)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		key.Field = name
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Sort sorts todo items by the keys, keeping the order of items that compare equal. Items without a value
+// for a key, such as a due date, come last whatever the direction.
+func Sort(todos []todo.TodoItem, keys []SortKey) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		for _, key := range keys {
+			cmp, ok := compareField(&todos[i], &todos[j], key.Field)
+			if !ok {
+				return cmp < 0
+			}
+			if cmp == 0 {
+				continue
+			}
+			if key.Descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// compareField compares the field of two todo items. If either item has no value for the field, ok is false
+// and cmp orders the item with the value first.
+func compareField(a, b *todo.TodoItem, field string) (cmp int, ok bool) {
+	switch field {
+	case "id":
+		return strings.Compare(a.ID, b.ID), true
+	case "num":
+		return a.Num - b.Num, true
+	case "title":
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)), true
+	case "done":
+		return compareBool(a.IsDone, b.IsDone), true
+	case "priority":
+		return importance(a.Priority) - importance(b.Priority), true
+	case "project":
+		return compareOptional(a.Project == "", b.Project == "", func() int {
+			return strings.Compare(strings.ToLower(a.Project), strings.ToLower(b.Project))
+		})
+	case "due":
+		return compareTime(a.DueAt, b.DueAt)
+	case "created":
+		return compareTime(a.CreatedAt, b.CreatedAt)
+	case "updated":
+		return compareTime(a.UpdatedAt, b.UpdatedAt)
+	case "completed":
+		return compareTime(a.CompletedAt, b.CompletedAt)
+	}
+	return 0, true
+}
+
+func compareBool(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if !a {
+		return -1
+	}
+	return 1
+}
+
+func compareTime(a, b time.Time) (int, bool) {
+	return compareOptional(a.IsZero(), b.IsZero(), func() int { return a.Compare(b) })
+}
+
+// compareOptional compares two values that may be unset, ordering set values first.
+func compareOptional(aUnset, bUnset bool, compare func() int) (int, bool) {
+	switch {
+	case aUnset && bUnset:
+		return 0, true
+	case aUnset:
+		return 1, false
+	case bUnset:
+		return -1, false
+	}
+	return compare(), true
+}
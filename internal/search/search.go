@@ -0,0 +1,129 @@
+// Package search implements full-text search across the titles and descriptions of todo items.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+// Weights of a term matching a word in the title or description. Whole words weigh more than prefixes.
+const (
+	titleWordWeight         = 3.0
+	titlePrefixWeight       = 2.0
+	descriptionWordWeight   = 1.0
+	descriptionPrefixWeight = 0.5
+	// allTermsBonus is added when every term of the search matches.
+	allTermsBonus = 5.0
+)
+
+// Result is a todo item matching a search, with its relevance score.
+type Result struct {
+	Todo  todo.TodoItem
+	Score float64
+}
+
+// Search returns the todo items matching any of the search terms, most relevant first. Items are ranked by
+// how many terms match and whether they match whole words in the title or only word prefixes in the
+// description. Items with the same score keep their order.
+func Search(todos []todo.TodoItem, query string) []Result {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []Result{}
+	}
+
+	results := []Result{}
+	for _, todoItem := range todos {
+		titleWords := Tokenize(todoItem.Title)
+		descriptionWords := Tokenize(todoItem.Description)
+
+		score := 0.0
+		matched := 0
+		for _, term := range terms {
+			termScore := scoreWords(titleWords, term, titleWordWeight, titlePrefixWeight) +
+				scoreWords(descriptionWords, term, descriptionWordWeight, descriptionPrefixWeight)
+			if termScore > 0 {
+				matched++
+				score += termScore
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+		if matched == len(terms) {
+			score += allTermsBonus
+		}
+		results = append(results, Result{Todo: todoItem, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// scoreWords scores the occurrences of the term among the words.
+func scoreWords(words []string, term string, wordWeight, prefixWeight float64) float64 {
+	score := 0.0
+	for _, word := range words {
+		if word == term {
+			score += wordWeight
+		} else if strings.HasPrefix(word, term) {
+			score += prefixWeight
+		}
+	}
+	return score
+}
+
+// Tokenize splits text into lowercase words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+// Highlight wraps the words of text starting with any of the query's terms in before and after, leaving the
+// rest of the text as is.
+func Highlight(text, query, before, after string) string {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if isSeparator(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && !isSeparator(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if matchesAny(strings.ToLower(word), terms) {
+			b.WriteString(before + word + after)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// matchesAny reports whether the word starts with any of the terms.
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeparator reports whether the rune separates words.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/hwkd/todo-cli/internal/todo"
+)
+
+func TestSearch(t *testing.T) {
+	todos := []todo.TodoItem{
+		{ID: "1", Title: "Buy milk", Description: "Deploy to the fridge"},
+		{ID: "2", Title: "Deploy app", Description: "Run the deployment pipeline"},
+		{ID: "3", Title: "Write report"},
+		{ID: "4", Title: "Check deployments"},
+	}
+
+	results := Search(todos, "DEPLOY")
+	want := []string{"2", "4", "1"}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d, got %d", len(want), len(results))
+	}
+	for i, id := range want {
+		if results[i].Todo.ID != id {
+			t.Errorf("Expected %s, got %s", id, results[i].Todo.ID)
+		}
+	}
+
+	// Matching every term ranks above matching one term more strongly.
+	results = Search(todos, "deploy pipeline")
+	if results[0].Todo.ID != "2" {
+		t.Errorf("Expected %s, got %s", "2", results[0].Todo.ID)
+	}
+
+	if results := Search(todos, "  "); len(results) != 0 {
+		t.Errorf("Expected %d, got %d", 0, len(results))
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("Deploy the app, then re-deploy!", "deploy APP", "[", "]")
+	want := "[Deploy] the [app], then re-[deploy]!"
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,98 @@
+package todo
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoArchive is returned when archiving todos of a TodoList without an archive.
+var ErrNoArchive = errors.New("No archive")
+
+// ArchivePath returns the path of the archive kept for the todo file at path, such as `todo.archive.csv` for
+// `todo.csv`. It has the same extension, so NewStore gives it the same kind of store.
+func ArchivePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".archive" + ext
+}
+
+// UseArchive makes store the archive that Archive moves done todos to. It is only loaded when needed, and
+// flushed along with the list.
+func (todoList *TodoList) UseArchive(store Store) {
+	todoList.archiveStore = store
+	todoList.archive = nil
+}
+
+// Archived returns the list of archived todos, loading it on first use.
+func (todoList *TodoList) Archived() (*TodoList, error) {
+	if todoList.archive != nil {
+		return todoList.archive, nil
+	}
+	if todoList.archiveStore == nil {
+		return nil, ErrNoArchive
+	}
+	archive, err := NewTodoList(todoList.archiveStore)
+	if err != nil {
+		return nil, err
+	}
+	todoList.archive = archive
+	return archive, nil
+}
+
+// Archive moves the TodoItems completed at or before the cutoff to the archive, and returns them. Todos
+// completed before completion times were recorded count as completed when last updated.
+func (todoList *TodoList) Archive(cutoff time.Time) ([]TodoItem, error) {
+	archived := []TodoItem{}
+	for _, todo := range todoList.Todos {
+		completedAt := todo.CompletedAt
+		if completedAt.IsZero() {
+			completedAt = todo.UpdatedAt
+		}
+		if todo.IsDone && !completedAt.After(cutoff) {
+			archived = append(archived, todo)
+		}
+	}
+	if len(archived) == 0 {
+		return archived, nil
+	}
+
+	archive, err := todoList.Archived()
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range archived {
+		i, _ := find(todoList.Todos, todo.ID)
+		todoList.Todos[i].archived = true
+		todoList.remove(&todoList.Todos, i)
+		archive.put(todo)
+	}
+	return archived, nil
+}
+
+// Unarchive moves the archived TodoItem matching id, as described by Get, back to the list.
+func (todoList *TodoList) Unarchive(id string) (*TodoItem, error) {
+	archive, err := todoList.Archived()
+	if err != nil {
+		return nil, err
+	}
+	i, err := find(archive.Todos, id)
+	if err != nil {
+		return nil, err
+	}
+	todo := archive.Todos[i]
+	archive.remove(&archive.Todos, i)
+
+	todo.archived = true
+	i = todoList.put(todo)
+	return &todoList.Todos[i], nil
+}
+
+// put inserts the todo into the list in the order of its number, keeping its number, and returns its index.
+func (todoList *TodoList) put(todo TodoItem) int {
+	todo.deleted = false
+	todo.dirty = true
+	todoList.nextNum = max(todoList.nextNum, todo.Num+1)
+	todoList.modified = true
+	return insertByNum(&todoList.Todos, todo)
+}
@@ -0,0 +1,127 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchivePath(t *testing.T) {
+	tests := map[string]string{
+		"todo.csv":          "todo.archive.csv",
+		"/home/me/.todo.db": "/home/me/.todo.archive.db",
+		"todo":              "todo.archive",
+	}
+	for path, want := range tests {
+		if got := ArchivePath(path); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestArchive(t *testing.T) {
+	for _, name := range []string{"todo.csv", "todo.json", "todo.db"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			open := func() *TodoList {
+				todoList, err := NewTodoList(NewStore(path))
+				if err != nil {
+					t.Fatalf("Expected nil, got %s", err)
+				}
+				todoList.UseJournal(NewJournal(JournalPath(path)))
+				todoList.UseArchive(NewStore(ArchivePath(path)))
+				return todoList
+			}
+			titles := func(todos []TodoItem) string {
+				titles := []string{}
+				for _, todo := range todos {
+					titles = append(titles, todo.Title)
+				}
+				return strings.Join(titles, ", ")
+			}
+			expect := func(list, archived string) {
+				t.Helper()
+				todoList := open()
+				archive, err := todoList.Archived()
+				if err != nil {
+					t.Fatalf("Expected nil, got %s", err)
+				}
+				if got := titles(todoList.List()); got != list {
+					t.Errorf("Expected list %q, got %q", list, got)
+				}
+				if got := titles(archive.List()); got != archived {
+					t.Errorf("Expected archive %q, got %q", archived, got)
+				}
+			}
+
+			todoList := open()
+			for i := 1; i <= 3; i++ {
+				todo := newTodoItem(t, fmt.Sprintf("Task %d", i), "")
+				todo.IsDone = i != 2
+				todoList.Add(*todo)
+			}
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+
+			// Only done todos updated before the cutoff are archived.
+			todoList = open()
+			if archived, err := todoList.Archive(time.Now().Add(-time.Hour)); err != nil || len(archived) != 0 {
+				t.Fatalf("Expected nothing archived, got %v, %v", archived, err)
+			}
+			archived, err := todoList.Archive(time.Now())
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if got := titles(archived); got != "Task 1, Task 3" {
+				t.Errorf("Expected %q, got %q", "Task 1, Task 3", got)
+			}
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect("Task 2", "Task 1, Task 3")
+
+			// An archived todo goes back to its place in the list, keeping its number.
+			todoList = open()
+			todo, err := todoList.Unarchive("1")
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if todo.Num != 1 {
+				t.Errorf("Expected %d, got %d", 1, todo.Num)
+			}
+			if _, err := todoList.Unarchive("2"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected %v, got %v", ErrNotFound, err)
+			}
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect("Task 1, Task 2", "Task 3")
+
+			// Undo moves todos back the other way.
+			entries, err := open().Undo(2)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if len(entries) != 2 || entries[0].String() != `unarchive "Task 1"` {
+				t.Errorf("Expected 2 entries, got %v", entries)
+			}
+			expect("Task 1, Task 2, Task 3", "")
+			if _, err := open().Redo(1); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect("Task 2", "Task 1, Task 3")
+		})
+	}
+
+	todoList, err := NewTodoList(NewStore(filepath.Join(t.TempDir(), "todo.csv")))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if _, err := todoList.Archived(); !errors.Is(err, ErrNoArchive) {
+		t.Errorf("Expected %v, got %v", ErrNoArchive, err)
+	}
+}
@@ -0,0 +1,86 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseDate parses a date relative to now, in now's location. The date can be followed by a time of day
+// such as `17:00`, otherwise it is midnight at the start of the day. It accepts:
+//   - `today`, `tomorrow` and `yesterday`
+//   - weekday names such as `fri` or `friday`, meaning the next such day, or today if it is one
+//   - offsets such as `+3d`, `+2w` and `+1m` for days, weeks and months from today
+//   - dates such as `2026-11-01`
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, fmt.Errorf("Expected a date such as today, fri, +3d or 2026-11-01, got %q", s)
+	}
+
+	date, err := parseDay(fields[0], now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(fields) == 1 {
+		return date, nil
+	}
+
+	clock, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Expected a time such as 17:00, got %q", fields[1])
+	}
+	// Set the wall clock rather than adding a duration, which would be off on days with a DST change.
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location()), nil
+}
+
+// parseDay parses the date part of ParseDate, returning midnight at the start of the day.
+func parseDay(s string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch s {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if weekday, ok := weekdays[s]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if strings.HasPrefix(s, "+") && len(s) > 2 {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("Expected an offset such as +3d, +2w or +1m, got %q", s)
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Expected a date such as today, fri, +3d or 2026-11-01, got %q", s)
+	}
+	return date, nil
+}
@@ -0,0 +1,81 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"today", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"tomorrow", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"fri", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"Monday", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"wed", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{"+3d", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"+2w", time.Date(2026, 10, 28, 0, 0, 0, 0, time.UTC)},
+		{"+1m", time.Date(2026, 11, 14, 0, 0, 0, 0, time.UTC)},
+		{"2026-11-01", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-11-01 17:00", time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)},
+		{"tomorrow 9:15", time.Date(2026, 10, 15, 9, 15, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, now)
+			if err != nil {
+				t.Errorf("Expected nil, got `%s`", err)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "someday", "+3y", "2026-13-01", "today 25:00"} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseDate(input, now); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseDateAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	// Clocks move forward on 2026-03-08 and back on 2026-11-01 in New York.
+	tests := []struct {
+		input string
+		now   time.Time
+		want  time.Time
+	}{
+		{"tomorrow", time.Date(2026, 3, 7, 9, 30, 0, 0, newYork), time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)},
+		{"+1d", time.Date(2026, 3, 7, 9, 30, 0, 0, newYork), time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)},
+		{"tomorrow 17:00", time.Date(2026, 3, 7, 9, 30, 0, 0, newYork), time.Date(2026, 3, 8, 17, 0, 0, 0, newYork)},
+		{"+2d", time.Date(2026, 3, 7, 9, 30, 0, 0, newYork), time.Date(2026, 3, 9, 0, 0, 0, 0, newYork)},
+		{"tomorrow", time.Date(2026, 10, 31, 9, 30, 0, 0, newYork), time.Date(2026, 11, 1, 0, 0, 0, 0, newYork)},
+		{"+1d 17:00", time.Date(2026, 10, 31, 9, 30, 0, 0, newYork), time.Date(2026, 11, 1, 17, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.now.Format("Jan 2 ")+tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, tt.now)
+			if err != nil {
+				t.Errorf("Expected nil, got `%s`", err)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDependencyCycle = errors.New("Dependency cycle")
+
+// AddDependency makes the TodoItem matching id depend on the one matching blockerID, both as described by
+// Get, so it is blocked until the blocker is done. It returns ErrDependencyCycle if the blocker already
+// depends on the todo, directly or through other todos.
+func (todoList *TodoList) AddDependency(id, blockerID string) error {
+	todo, err := todoList.Get(id)
+	if err != nil {
+		return err
+	}
+	blocker, err := todoList.Get(blockerID)
+	if err != nil {
+		return err
+	}
+	if todo.ID == blocker.ID {
+		return fmt.Errorf("%w: %s cannot depend on itself", ErrDependencyCycle, todo.ID)
+	}
+	if path := todoList.dependencyPath(blocker.ID, todo.ID); path != nil {
+		return fmt.Errorf("%w: %s already depends on %s through %s", ErrDependencyCycle, blocker.ID, todo.ID, strings.Join(path, " -> "))
+	}
+	if todo.DependsOn(blocker.ID) {
+		return nil
+	}
+
+	updated := *todo
+	updated.Dependencies = append(append([]string{}, todo.Dependencies...), blocker.ID)
+	todoList.Update(updated)
+	return nil
+}
+
+// RemoveDependency makes the TodoItem matching id no longer depend on the one matching blockerID, both as
+// described by Get. The blocker can also be the ID of a todo that was purged.
+func (todoList *TodoList) RemoveDependency(id, blockerID string) error {
+	todo, err := todoList.Get(id)
+	if err != nil {
+		return err
+	}
+	if i, err := find(todoList.all(), blockerID); err == nil {
+		blockerID = todoList.all()[i].ID
+	}
+	if !todo.DependsOn(blockerID) {
+		return fmt.Errorf("%w: %s does not depend on %s", ErrNotFound, todo.ID, blockerID)
+	}
+
+	updated := *todo
+	updated.Dependencies = []string{}
+	for _, dependency := range todo.Dependencies {
+		if dependency != blockerID {
+			updated.Dependencies = append(updated.Dependencies, dependency)
+		}
+	}
+	todoList.Update(updated)
+	return nil
+}
+
+// DependsOn reports whether the todo depends directly on the todo with the ID.
+func (todo *TodoItem) DependsOn(id string) bool {
+	for _, dependency := range todo.Dependencies {
+		if dependency == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Blockers returns the incomplete TodoItems in the list that the todo depends on. Todos in the trash or
+// the archive don't block.
+func (todoList *TodoList) Blockers(todo TodoItem) []TodoItem {
+	blockers := []TodoItem{}
+	for _, blocker := range todoList.Todos {
+		if !blocker.IsDone && todo.DependsOn(blocker.ID) {
+			blockers = append(blockers, blocker)
+		}
+	}
+	return blockers
+}
+
+// Blocked returns the IDs of the incomplete TodoItems in the list that are blocked by other todos.
+func (todoList *TodoList) Blocked() map[string]bool {
+	blocked := map[string]bool{}
+	for _, todo := range todoList.Todos {
+		if !todo.IsDone && len(todoList.Blockers(todo)) > 0 {
+			blocked[todo.ID] = true
+		}
+	}
+	return blocked
+}
+
+// DropCycles drops the parents and dependencies of todos, about to be added to the list or to replace the todos
+// with their IDs, that would make a todo its own ancestor or depend on itself, directly or through other todos
+// of the list or of todos. Relations are checked in the order of todos, so of two todos depending on each other
+// the second one's dependency is dropped. It returns the number of relations dropped.
+func (todoList *TodoList) DropCycles(todos []TodoItem) int {
+	parents := map[string]string{}
+	dependencies := map[string][]string{}
+	for _, todo := range todoList.all() {
+		parents[todo.ID] = todo.ParentID
+		dependencies[todo.ID] = todo.Dependencies
+	}
+	for _, todo := range todos {
+		delete(parents, todo.ID)
+		delete(dependencies, todo.ID)
+	}
+
+	// reaches reports whether following next from the ID from leads to the ID to.
+	reaches := func(from, to string, next func(id string) []string) bool {
+		visited := map[string]bool{}
+		stack := []string{from}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if id == to {
+				return true
+			}
+			if !visited[id] {
+				visited[id] = true
+				stack = append(stack, next(id)...)
+			}
+		}
+		return false
+	}
+	parentOf := func(id string) []string {
+		if parent := parents[id]; parent != "" {
+			return []string{parent}
+		}
+		return nil
+	}
+	dependenciesOf := func(id string) []string {
+		return dependencies[id]
+	}
+
+	dropped := 0
+	for i := range todos {
+		todo := &todos[i]
+		if todo.ParentID != "" && reaches(todo.ParentID, todo.ID, parentOf) {
+			todo.ParentID = ""
+			dropped++
+		}
+		parents[todo.ID] = todo.ParentID
+
+		kept := []string{}
+		for _, dependency := range todo.Dependencies {
+			if reaches(dependency, todo.ID, dependenciesOf) {
+				dropped++
+				continue
+			}
+			kept = append(kept, dependency)
+			dependencies[todo.ID] = kept
+		}
+		if len(kept) < len(todo.Dependencies) {
+			todo.Dependencies = kept
+		}
+	}
+	return dropped
+}
+
+// dependencyPath returns the IDs of the todos leading from the todo with the ID from to the one with the ID
+// to by following dependencies, from included, or nil if from doesn't depend on to.
+func (todoList *TodoList) dependencyPath(from, to string) []string {
+	dependencies := map[string][]string{}
+	for _, todo := range todoList.all() {
+		dependencies[todo.ID] = todo.Dependencies
+	}
+	visited := map[string]bool{}
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		if id == to {
+			return []string{id}
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, dependency := range dependencies[id] {
+			if path := visit(dependency); path != nil {
+				return append([]string{id}, path...)
+			}
+		}
+		return nil
+	}
+	return visit(from)
+}
@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	todoList := makeTree(t, path)
+
+	// Book venue waits for Compare prices, which waits for Buy milk.
+	if err := todoList.AddDependency("v2", "c3"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if err := todoList.AddDependency("c3", "m5"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if err := todoList.AddDependency("c3", "m5"); err != nil {
+		t.Errorf("Expected nil for a duplicate dependency, got %s", err)
+	}
+	for _, tt := range []struct{ id, blockerID string }{{"m5", "v2"}, {"m5", "c3"}, {"v2", "v2"}} {
+		if err := todoList.AddDependency(tt.id, tt.blockerID); !errors.Is(err, ErrDependencyCycle) {
+			t.Errorf("Expected %v for %s on %s, got %v", ErrDependencyCycle, tt.id, tt.blockerID, err)
+		}
+	}
+	if err := todoList.AddDependency("v2", "x9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todo, _ := todoList.Get("c3")
+	if len(todo.Dependencies) != 1 || todo.Dependencies[0] != "m5" {
+		t.Errorf("Expected [m5], got %v", todo.Dependencies)
+	}
+	blocked := todoList.Blocked()
+	if len(blocked) != 2 || !blocked["v2"] || !blocked["c3"] {
+		t.Errorf("Expected v2 and c3 blocked, got %v", blocked)
+	}
+
+	// Completing a blocker unblocks the todos depending on it.
+	todo, _ = todoList.Get("m5")
+	todo.Done()
+	todoList.Update(*todo)
+	if blocked := todoList.Blocked(); len(blocked) != 1 || !blocked["v2"] {
+		t.Errorf("Expected v2 blocked, got %v", blocked)
+	}
+
+	if err := todoList.RemoveDependency("v2", "c3"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if err := todoList.RemoveDependency("v2", "c3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+	if blocked := todoList.Blocked(); len(blocked) != 0 {
+		t.Errorf("Expected none blocked, got %v", blocked)
+	}
+}
+
+func TestDropCycles(t *testing.T) {
+	todoList := makeTree(t, filepath.Join(t.TempDir(), "todo.json"))
+	if err := todoList.AddDependency("c3", "m5"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	todos := []TodoItem{
+		{ID: "m5", Dependencies: []string{"c3", "x9"}},
+		{ID: "p1", ParentID: "c3"},
+		{ID: "n6", Dependencies: []string{"n7"}},
+		{ID: "n7", Dependencies: []string{"n6"}},
+		{ID: "i4", ParentID: "m5"},
+		{ID: "s8", ParentID: "s8"},
+	}
+	if dropped := todoList.DropCycles(todos); dropped != 4 {
+		t.Errorf("Expected %d, got %d", 4, dropped)
+	}
+	want := []struct {
+		parentID     string
+		dependencies string
+	}{
+		{"", "x9"},
+		{"", ""},
+		{"", "n7"},
+		{"", ""},
+		{"m5", ""},
+		{"", ""},
+	}
+	for i, todo := range todos {
+		if todo.ParentID != want[i].parentID || strings.Join(todo.Dependencies, ",") != want[i].dependencies {
+			t.Errorf("Expected parent %q and dependencies %q for %s, got %q and %v", want[i].parentID, want[i].dependencies, todo.ID, todo.ParentID, todo.Dependencies)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package todo
+
+import "time"
+
+// HasDue reports whether the todo has a due date.
+func (todo *TodoItem) HasDue() bool {
+	return !todo.DueAt.IsZero()
+}
+
+// DueDateOnly reports whether the due date has no time of day, in which case the todo is due by the end of
+// that day.
+func (todo *TodoItem) DueDateOnly() bool {
+	due := todo.DueAt.Local()
+	return due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 && due.Nanosecond() == 0
+}
+
+// IsOverdue reports whether the todo is incomplete and its due date has passed at now.
+func (todo *TodoItem) IsOverdue(now time.Time) bool {
+	if todo.IsDone || !todo.HasDue() {
+		return false
+	}
+	if todo.DueDateOnly() {
+		return startOfDay(now).After(startOfDay(todo.DueAt))
+	}
+	return now.After(todo.DueAt)
+}
+
+// IsDueToday reports whether the todo is incomplete, due on the same day as now, and not yet overdue.
+func (todo *TodoItem) IsDueToday(now time.Time) bool {
+	if todo.IsDone || !todo.HasDue() || todo.IsOverdue(now) {
+		return false
+	}
+	return startOfDay(now).Equal(startOfDay(todo.DueAt))
+}
+
+// startOfDay returns midnight at the start of t's day in the local time zone.
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
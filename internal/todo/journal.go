@@ -0,0 +1,282 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// journalLimit is the number of entries kept for undo.
+const journalLimit = 100
+
+// ErrNoJournal is returned when undoing or redoing changes to a TodoList without a journal.
+var ErrNoJournal = errors.New("No journal of changes")
+
+// JournalPath returns the path of the journal kept for the todo file at path.
+func JournalPath(path string) string {
+	return path + ".journal"
+}
+
+// JournalChange is a change to a single todo. Before is nil for an added todo, and After is nil for a
+// purged one. Archived is set when the todo was moved to or from the archive instead.
+type JournalChange struct {
+	Before   *TodoItem `json:"before,omitempty"`
+	After    *TodoItem `json:"after,omitempty"`
+	Archived bool      `json:"archived,omitempty"`
+}
+
+// String describes the change, such as `delete "Buy milk"`.
+func (c JournalChange) String() string {
+	switch {
+	case c.Archived && c.Before == nil:
+		return fmt.Sprintf("unarchive %q", c.After.Title)
+	case c.Archived && c.After == nil:
+		return fmt.Sprintf("archive %q", c.Before.Title)
+	case c.Before == nil:
+		return fmt.Sprintf("add %q", c.After.Title)
+	case c.After == nil:
+		return fmt.Sprintf("purge %q", c.Before.Title)
+	case !c.Before.IsDeleted() && c.After.IsDeleted():
+		return fmt.Sprintf("delete %q", c.After.Title)
+	case c.Before.IsDeleted() && !c.After.IsDeleted():
+		return fmt.Sprintf("restore %q", c.After.Title)
+	case !c.Before.IsDone && c.After.IsDone:
+		return fmt.Sprintf("complete %q", c.After.Title)
+	case c.Before.IsDone && !c.After.IsDone:
+		return fmt.Sprintf("mark incomplete %q", c.After.Title)
+	default:
+		return fmt.Sprintf("update %q", c.After.Title)
+	}
+}
+
+// JournalEntry holds the changes written by one flush of a TodoList.
+type JournalEntry struct {
+	At      time.Time       `json:"at"`
+	Changes []JournalChange `json:"changes"`
+}
+
+// String describes the changes of the entry.
+func (e JournalEntry) String() string {
+	changes := make([]string, len(e.Changes))
+	for i, change := range e.Changes {
+		changes[i] = change.String()
+	}
+	return strings.Join(changes, ", ")
+}
+
+// Journal records the changes flushed to a store, so they can be undone and redone. It is kept in a JSON
+// file next to the store file.
+type Journal struct {
+	path string
+	// Undo holds the entries that can be undone, oldest first, and Redo those that were undone, most
+	// recently undone last.
+	Undo []JournalEntry `json:"undo"`
+	Redo []JournalEntry `json:"redo"`
+}
+
+// NewJournal creates a Journal kept in the file at path.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// record adds an entry that can be undone, and forgets the entries that could be redone.
+func (j *Journal) record(entry JournalEntry) error {
+	if err := j.load(); err != nil {
+		return err
+	}
+	j.Undo = append(j.Undo, entry)
+	if len(j.Undo) > journalLimit {
+		j.Undo = j.Undo[len(j.Undo)-journalLimit:]
+	}
+	j.Redo = nil
+	return j.save()
+}
+
+// load reads the journal file. A missing file is an empty journal.
+func (j *Journal) load() error {
+	j.Undo, j.Redo = nil, nil
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return fmt.Errorf("%w: Error reading journal %s", err, j.path)
+	}
+	return nil
+}
+
+// save writes the journal file. Unlike store files, it has no backup.
+func (j *Journal) save() error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(j); err != nil {
+		return fmt.Errorf("%w: Error writing journal", err)
+	}
+	return writeFile(j.path, buf.Bytes(), false)
+}
+
+// UseJournal makes Flush record the changes it writes to journal, so they can be undone.
+func (todoList *TodoList) UseJournal(journal *Journal) {
+	todoList.journal = journal
+}
+
+// Undo reverts the last n flushed changes, most recent first, and flushes the list. It returns the entries
+// undone, which are fewer than n if the journal runs out.
+func (todoList *TodoList) Undo(n int) ([]JournalEntry, error) {
+	return todoList.replay(n, true)
+}
+
+// Redo applies again the last n changes undone, and flushes the list. It returns the entries redone, which
+// are fewer than n if there are not as many to redo.
+func (todoList *TodoList) Redo(n int) ([]JournalEntry, error) {
+	return todoList.replay(n, false)
+}
+
+// replay moves up to n entries from the undo stack to the redo stack when undoing, or back when redoing, and
+// applies them to the list.
+func (todoList *TodoList) replay(n int, undo bool) ([]JournalEntry, error) {
+	journal := todoList.journal
+	if journal == nil {
+		return nil, ErrNoJournal
+	}
+	if err := journal.load(); err != nil {
+		return nil, err
+	}
+
+	from, to := &journal.Undo, &journal.Redo
+	if !undo {
+		from, to = &journal.Redo, &journal.Undo
+	}
+	entries := []JournalEntry{}
+	for len(entries) < n && len(*from) > 0 {
+		entry := (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+		if undo {
+			for i := len(entry.Changes) - 1; i >= 0; i-- {
+				change := entry.Changes[i]
+				if err := todoList.revert(change.Before, change.After, change.Archived); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			for _, change := range entry.Changes {
+				if err := todoList.revert(change.After, change.Before, change.Archived); err != nil {
+					return nil, err
+				}
+			}
+		}
+		*to = append(*to, entry)
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	if err := todoList.flush(false); err != nil {
+		return nil, err
+	}
+	return entries, journal.save()
+}
+
+// revert puts back the todo to state with restore. If the todo was moved to or from the archive, it is moved
+// back the other way.
+func (todoList *TodoList) revert(state, current *TodoItem, archived bool) error {
+	todoList.restore(state, current)
+	if !archived {
+		return nil
+	}
+	archive, err := todoList.Archived()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		archive.restore(current, nil)
+	} else {
+		archive.restore(nil, state)
+	}
+	return nil
+}
+
+// restore puts back the todo to state, in the list or the trash, removing it if state is nil. current is the
+// todo the change being reverted left behind, which identifies it when state is nil.
+func (todoList *TodoList) restore(state, current *TodoItem) {
+	var id string
+	if state != nil {
+		id = state.ID
+	} else {
+		id = current.ID
+	}
+	for _, todos := range []*[]TodoItem{&todoList.Todos, &todoList.Trashed} {
+		for i := range *todos {
+			if (*todos)[i].ID == id {
+				if state == nil {
+					todoList.remove(todos, i)
+				} else {
+					*todos = append((*todos)[:i], (*todos)[i+1:]...)
+				}
+				break
+			}
+		}
+	}
+	if state == nil {
+		return
+	}
+
+	todo := *state
+	todo.dirty = true
+	if todo.IsDeleted() {
+		insertByNum(&todoList.Trashed, todo)
+	} else {
+		insertByNum(&todoList.Todos, todo)
+	}
+	todoList.nextNum = max(todoList.nextNum, todo.Num+1)
+	todoList.modified = true
+}
+
+// journalEntry returns the changes made to the list since it was loaded or last flushed, leaving out todos
+// that were updated back to how they were.
+func (todoList *TodoList) journalEntry() JournalEntry {
+	entry := JournalEntry{At: time.Now(), Changes: []JournalChange{}}
+	for _, todo := range todoList.changes() {
+		change := JournalChange{}
+		if before, ok := todoList.snapshot[todo.ID]; ok {
+			change.Before = &before
+		}
+		if !todo.deleted {
+			after := cloneTodo(todo)
+			change.After = &after
+		}
+		change.Archived = todo.archived
+		if change.Before != nil && change.After != nil && reflect.DeepEqual(*change.Before, *change.After) {
+			continue
+		}
+		entry.Changes = append(entry.Changes, change)
+	}
+	return entry
+}
+
+// takeSnapshot records the todos as flushed, to tell what changed by the next flush.
+func (todoList *TodoList) takeSnapshot() {
+	todoList.snapshot = make(map[string]TodoItem, len(todoList.Todos)+len(todoList.Trashed))
+	for _, todo := range todoList.all() {
+		todoList.snapshot[todo.ID] = cloneTodo(todo)
+	}
+}
+
+// cloneTodo copies the todo without sharing its tags or dependencies, and with the bookkeeping flags cleared.
+func cloneTodo(todo TodoItem) TodoItem {
+	todo.Tags = append([]string(nil), todo.Tags...)
+	todo.Dependencies = append([]string(nil), todo.Dependencies...)
+	todo.dirty = false
+	todo.deleted = false
+	todo.archived = false
+	return todo
+}
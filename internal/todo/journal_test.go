@@ -0,0 +1,173 @@
+package todo
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	for _, name := range []string{"todo.csv", "todo.json", "todo.db"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			open := func() *TodoList {
+				todoList, err := NewTodoList(NewStore(path))
+				if err != nil {
+					t.Fatalf("Expected nil, got %s", err)
+				}
+				todoList.UseJournal(NewJournal(JournalPath(path)))
+				return todoList
+			}
+			titles := func(todoList *TodoList) []string {
+				titles := []string{}
+				for _, todo := range todoList.List() {
+					state := todo.Title
+					if todo.IsDone {
+						state += " (done)"
+					}
+					titles = append(titles, state)
+				}
+				return titles
+			}
+			expect := func(want ...string) {
+				t.Helper()
+				got := titles(open())
+				if len(got) != len(want) {
+					t.Fatalf("Expected %v, got %v", want, got)
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Errorf("Expected %v, got %v", want, got)
+					}
+				}
+			}
+
+			// Each invocation of the CLI loads the list, changes it and flushes it.
+			todoList := open()
+			todoList.Add(*newTodoItem(t, "Task 1", ""))
+			todoList.Add(*newTodoItem(t, "Task 2", ""))
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			todoList = open()
+			todo, _ := todoList.Get("1")
+			todo.Done()
+			todoList.Update(*todo)
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			todoList = open()
+			if err := todoList.Delete("2"); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect("Task 1 (done)")
+			if _, err := os.Stat(JournalPath(path) + ".bak"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Expected no journal backup, got %v", err)
+			}
+
+			entries, err := open().Undo(1)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if len(entries) != 1 || entries[0].String() != `delete "Task 2"` {
+				t.Errorf("Expected %s, got %v", `delete "Task 2"`, entries)
+			}
+			expect("Task 1 (done)", "Task 2")
+
+			if _, err := open().Undo(2); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect()
+
+			// Undoing more than the journal holds stops at the start.
+			if entries, err := open().Undo(5); err != nil || len(entries) != 0 {
+				t.Errorf("Expected nothing to undo, got %v, %v", entries, err)
+			}
+
+			if _, err := open().Redo(2); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			expect("Task 1 (done)", "Task 2")
+
+			// A new change can't be followed by a redo of the changes undone before it.
+			todoList = open()
+			todoList.Add(*newTodoItem(t, "Task 3", ""))
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if entries, err := open().Redo(1); err != nil || len(entries) != 0 {
+				t.Errorf("Expected nothing to redo, got %v, %v", entries, err)
+			}
+			expect("Task 1 (done)", "Task 2", "Task 3")
+		})
+	}
+
+	todoList, err := NewTodoList(NewTodoListCsvStore(filepath.Join(t.TempDir(), "todo.csv")))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if _, err := todoList.Undo(1); err != ErrNoJournal {
+		t.Errorf("Expected %v, got %v", ErrNoJournal, err)
+	}
+}
+
+func TestJournalUnrecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	open := func() *TodoList {
+		todoList, err := NewTodoList(NewStore(path))
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		todoList.UseJournal(NewJournal(JournalPath(path)))
+		return todoList
+	}
+
+	todoList := open()
+	todoList.Add(*newTodoItem(t, "Task 1", ""))
+	todoList.Add(*newTodoItem(t, "Task 2", ""))
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todoList = open()
+	if err := todoList.Delete("1"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	// The CLI purges the expired trash before each command, including undo.
+	todoList = open()
+	if purged := todoList.Purge(time.Now()); len(purged) != 1 {
+		t.Fatalf("Expected 1 purged todo, got %v", purged)
+	}
+	if err := todoList.FlushUnrecorded(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	entries, err := todoList.Undo(1)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(entries) != 1 || entries[0].String() != `delete "Task 1"` {
+		t.Errorf("Expected %s, got %v", `delete "Task 1"`, entries)
+	}
+	if todos := open().List(); len(todos) != 2 {
+		t.Errorf("Expected 2 todos, got %v", todos)
+	}
+}
+
+func TestCloneTodo(t *testing.T) {
+	todo := TodoItem{Title: "Task", Tags: []string{"home"}, Dependencies: []string{"c3"}}
+	clone := cloneTodo(todo)
+	clone.Tags[0] = "work"
+	clone.Dependencies[0] = "m5"
+	if todo.Tags[0] != "home" || todo.Dependencies[0] != "c3" {
+		t.Errorf("Expected [home] and [c3], got %v and %v", todo.Tags, todo.Dependencies)
+	}
+}
@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLocked is returned when the lock on a todo file could not be acquired in time.
+var ErrLocked = errors.New("Todo file is locked by another process")
+
+// errLockBusy is returned by tryLock when another process holds the lock.
+var errLockBusy = errors.New("lock busy")
+
+// lockTimeout is how long LockFile waits for another process to release the lock.
+var lockTimeout = 10 * time.Second
+
+// FileLock is an exclusive advisory lock held on a lock file. Holding it around loading, modifying and
+// flushing a TodoList keeps concurrent invocations from overwriting each other's changes.
+type FileLock struct {
+	file *os.File
+}
+
+// LockPath returns the path of the lock file guarding the todo file at path.
+func LockPath(path string) string {
+	return path + ".lock"
+}
+
+// LockFile acquires the lock on the file at path, creating the file if needed. It waits for other
+// processes to release the lock and returns ErrLocked if they do not do so in time.
+func LockFile(path string) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLock(file)
+		if err == nil {
+			return &FileLock{file: file}, nil
+		}
+		if !errors.Is(err, errLockBusy) {
+			file.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
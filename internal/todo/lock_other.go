@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package todo
+
+import "os"
+
+// tryLock is a no-op on platforms without file locking; conflicts are still caught when saving.
+func tryLock(file *os.File) error {
+	return nil
+}
+
+// unlock is a no-op on platforms without file locking.
+func unlock(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package todo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	defer func(timeout time.Duration) { lockTimeout = timeout }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+
+	path := LockPath(filepath.Join(t.TempDir(), "todo.csv"))
+	lock, err := LockFile(path)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	if _, err := LockFile(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected %s, got %v", ErrLocked, err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("Expected nil, got %s", err)
+	}
+	lock, err = LockFile(path)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	lock.Unlock()
+}
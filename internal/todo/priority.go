@@ -0,0 +1,60 @@
+package todo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Priority is the importance of a TodoItem, from "A" (highest) to "Z" (lowest) like todo.txt, or
+// PriorityNone.
+type Priority string
+
+const PriorityNone Priority = ""
+
+// ParsePriority parses a priority letter from A to Z, case insensitively, or one of the names
+// `high` (A), `medium` (B), `low` (C) and `none`.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(s) {
+	case "none", "":
+		return PriorityNone, nil
+	case "high":
+		return "A", nil
+	case "medium":
+		return "B", nil
+	case "low":
+		return "C", nil
+	}
+	if len(s) == 1 {
+		if c := strings.ToUpper(s)[0]; c >= 'A' && c <= 'Z' {
+			return Priority(c), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("Expected priority as A-Z, high, medium, low or none, got %s", s)
+}
+
+// Less reports whether p is more important than other. PriorityNone is the least important.
+func (p Priority) Less(other Priority) bool {
+	if p == PriorityNone {
+		return false
+	}
+	if other == PriorityNone {
+		return true
+	}
+	return p < other
+}
+
+// String returns the priority letter, or "-" for PriorityNone.
+func (p Priority) String() string {
+	if p == PriorityNone {
+		return "-"
+	}
+	return string(p)
+}
+
+// SortByPriority sorts todos by priority, most important first, keeping the order of equal priorities.
+func SortByPriority(todos []TodoItem) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Priority.Less(todos[j].Priority)
+	})
+}
@@ -0,0 +1,349 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("Invalid recurrence")
+
+// Recurrence is a rule repeating a TodoItem, stored as the value of an iCalendar RRULE such as
+// `FREQ=WEEKLY;BYDAY=MO,TH`. Only the DAILY, WEEKLY and MONTHLY frequencies with INTERVAL, BYDAY and
+// BYMONTHDAY are supported. Rules counting from when the todo is completed rather than from its due date have
+// the extension part `X-FROM=COMPLETION`. RecurrenceNone doesn't repeat.
+type Recurrence string
+
+const RecurrenceNone Recurrence = ""
+
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+)
+
+// rruleWeekdays are the RRULE codes of the weekdays, indexed by time.Weekday.
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// rule is a parsed Recurrence.
+type rule struct {
+	freq     string
+	interval int
+	// weekdays are the days of the week a weekly rule repeats on, and monthDay the day of the month a monthly
+	// rule repeats on. They are unset to repeat on the day of the due date.
+	weekdays       []time.Weekday
+	monthDay       int
+	fromCompletion bool
+}
+
+// ParseRecurrence parses a recurrence, case insensitively, as one of:
+//   - `daily`, `weekly` or `monthly`, repeating from the due date
+//   - `weekly:mon,thu`, repeating on the given weekdays
+//   - `monthly:15`, repeating on the given day of the month, or its last day in shorter months
+//   - `+3d`, `+2w` or `+1m`, repeating that many days, weeks or months after the todo is completed
+//   - an RRULE value such as `FREQ=WEEKLY;INTERVAL=2;BYDAY=MO`
+//   - `none`, which doesn't repeat
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "none") {
+		return RecurrenceNone, nil
+	}
+	if strings.Contains(s, "=") {
+		r, err := parseRRule(s)
+		if err != nil {
+			return RecurrenceNone, err
+		}
+		return r.format(), nil
+	}
+
+	name, arg, hasArg := strings.Cut(strings.ToLower(s), ":")
+	r := rule{interval: 1}
+	switch {
+	case name == "daily" && !hasArg:
+		r.freq = freqDaily
+	case name == "weekly":
+		r.freq = freqWeekly
+		if hasArg {
+			for _, day := range strings.Split(arg, ",") {
+				weekday, ok := parseWeekday(day)
+				if !ok {
+					return RecurrenceNone, fmt.Errorf("%w: Expected a weekday such as mon, got %s", ErrInvalidRecurrence, day)
+				}
+				r.weekdays = append(r.weekdays, weekday)
+			}
+		}
+	case name == "monthly":
+		r.freq = freqMonthly
+		if hasArg {
+			day, err := strconv.Atoi(arg)
+			if err != nil || day < 1 || day > 31 {
+				return RecurrenceNone, fmt.Errorf("%w: Expected a day of the month from 1 to 31, got %s", ErrInvalidRecurrence, arg)
+			}
+			r.monthDay = day
+		}
+	case strings.HasPrefix(name, "+") && len(name) > 2 && !hasArg:
+		n, err := strconv.Atoi(name[1 : len(name)-1])
+		freq := map[byte]string{'d': freqDaily, 'w': freqWeekly, 'm': freqMonthly}[name[len(name)-1]]
+		if err != nil || n < 1 || freq == "" {
+			return RecurrenceNone, fmt.Errorf("%w: Expected an interval such as +3d, +2w or +1m, got %s", ErrInvalidRecurrence, s)
+		}
+		r.freq, r.interval, r.fromCompletion = freq, n, true
+	default:
+		return RecurrenceNone, fmt.Errorf("%w: Expected daily, weekly[:mon,thu], monthly[:15], +3d or an RRULE, got %s", ErrInvalidRecurrence, s)
+	}
+	return r.format(), nil
+}
+
+// parseWeekday parses a weekday name such as `mon` or `monday`, or an RRULE code such as `MO`.
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String()), s) && strings.EqualFold(s[:2], rruleWeekdays[day]) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parseRRule parses the supported parts of an RRULE value.
+func parseRRule(s string) (rule, error) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(strings.ToUpper(s), ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			if value != freqDaily && value != freqWeekly && value != freqMonthly {
+				return rule{}, fmt.Errorf("%w: Expected FREQ as DAILY, WEEKLY or MONTHLY, got %s", ErrInvalidRecurrence, value)
+			}
+			r.freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule{}, fmt.Errorf("%w: Expected INTERVAL as a positive number, got %s", ErrInvalidRecurrence, value)
+			}
+			r.interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := parseWeekday(code)
+				if !ok || len(code) != 2 {
+					return rule{}, fmt.Errorf("%w: Expected BYDAY as weekdays such as MO,TH, got %s", ErrInvalidRecurrence, value)
+				}
+				r.weekdays = append(r.weekdays, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return rule{}, fmt.Errorf("%w: Expected BYMONTHDAY from 1 to 31, got %s", ErrInvalidRecurrence, value)
+			}
+			r.monthDay = day
+		case "X-FROM":
+			if value != "COMPLETION" {
+				return rule{}, fmt.Errorf("%w: Expected X-FROM as COMPLETION, got %s", ErrInvalidRecurrence, value)
+			}
+			r.fromCompletion = true
+		default:
+			return rule{}, fmt.Errorf("%w: Unsupported RRULE part %s", ErrInvalidRecurrence, part)
+		}
+	}
+
+	switch {
+	case r.freq == "":
+		return rule{}, fmt.Errorf("%w: Missing FREQ in %s", ErrInvalidRecurrence, s)
+	case len(r.weekdays) > 0 && r.freq != freqWeekly:
+		return rule{}, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRecurrence)
+	case r.monthDay > 0 && r.freq != freqMonthly:
+		return rule{}, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRecurrence)
+	case r.fromCompletion && (len(r.weekdays) > 0 || r.monthDay > 0):
+		return rule{}, fmt.Errorf("%w: X-FROM=COMPLETION cannot be used with BYDAY or BYMONTHDAY", ErrInvalidRecurrence)
+	}
+	return r, nil
+}
+
+// format formats the rule as an RRULE value, with the weekdays in order from Monday.
+func (r rule) format() Recurrence {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.interval))
+	}
+	if len(r.weekdays) > 0 {
+		codes := []string{}
+		for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+			if r.hasWeekday(day) {
+				codes = append(codes, rruleWeekdays[day])
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.monthDay > 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", r.monthDay))
+	}
+	if r.fromCompletion {
+		parts = append(parts, "X-FROM=COMPLETION")
+	}
+	return Recurrence(strings.Join(parts, ";"))
+}
+
+func (r rule) hasWeekday(day time.Weekday) bool {
+	for _, weekday := range r.weekdays {
+		if weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// RRule returns the recurrence as an iCalendar RRULE value, without the parts iCalendar doesn't define.
+func (r Recurrence) RRule() string {
+	parts := []string{}
+	for _, part := range strings.Split(string(r), ";") {
+		if !strings.HasPrefix(part, "X-") {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns when the occurrence following one due at due and completed at completedAt is due. A todo
+// without a due date counts as due at the start of the day it was completed. Rules repeating from the due date
+// skip the occurrences on or before the day the todo was completed, so a late todo is not followed by one
+// already overdue.
+func (r Recurrence) Next(due, completedAt time.Time) (time.Time, error) {
+	rule, err := parseRRule(string(r))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	completedAt = completedAt.Local()
+	if due.IsZero() {
+		due = startOfDay(completedAt)
+	}
+	due = due.Local()
+	if rule.fromCompletion {
+		// Keep the time of day of the due date.
+		from := time.Date(completedAt.Year(), completedAt.Month(), completedAt.Day(), due.Hour(), due.Minute(), due.Second(), 0, time.Local)
+		return rule.advance(from), nil
+	}
+
+	next := rule.advance(due)
+	for !startOfDay(next).After(startOfDay(completedAt)) {
+		next = rule.advance(next)
+	}
+	return next, nil
+}
+
+// advance returns the occurrence of the rule following t.
+func (r rule) advance(t time.Time) time.Time {
+	switch r.freq {
+	case freqWeekly:
+		if len(r.weekdays) == 0 {
+			return t.AddDate(0, 0, 7*r.interval)
+		}
+		// Weeks start on Monday, as RRULE's default WKST.
+		daysFromMonday := (int(t.Weekday()) + 6) % 7
+		for i := 1; daysFromMonday+i < 7; i++ {
+			if day := t.AddDate(0, 0, i); r.hasWeekday(day.Weekday()) {
+				return day
+			}
+		}
+		monday := t.AddDate(0, 0, 7*r.interval-daysFromMonday)
+		for i := 0; ; i++ {
+			if day := monday.AddDate(0, 0, i); r.hasWeekday(day.Weekday()) {
+				return day
+			}
+		}
+	case freqMonthly:
+		if r.monthDay > 0 {
+			if day := monthDay(t, 0, r.monthDay); day.After(t) {
+				return day
+			}
+			return monthDay(t, r.interval, r.monthDay)
+		}
+		return monthDay(t, r.interval, t.Day())
+	default:
+		return t.AddDate(0, 0, r.interval)
+	}
+}
+
+// monthDay returns the day of the month months after t's, at t's time of day. A day past the end of the
+// month is its last day.
+func monthDay(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+// onDay returns the recurrence with a plain monthly rule pinned to the day of the month of from, as BYMONTHDAY,
+// so that an occurrence moved to the end of a shorter month goes back to the day in the months after. Other
+// rules are returned unchanged.
+func (r Recurrence) onDay(from time.Time) Recurrence {
+	rule, err := parseRRule(string(r))
+	if err != nil || rule.freq != freqMonthly || rule.monthDay > 0 || rule.fromCompletion {
+		return r
+	}
+	rule.monthDay = from.Local().Day()
+	return rule.format()
+}
+
+// NextOccurrence returns a new todo repeating the completed todo, due at the next occurrence of its
+// Recurrence. It is a copy of the todo with a new ID, no number, and IsDone unset. A plain monthly Recurrence
+// is pinned to the day of the due date, or of completion if there is none.
+func (todo *TodoItem) NextOccurrence() (*TodoItem, error) {
+	completedAt := todo.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+	due, err := todo.Recurrence.Next(todo.DueAt, completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := NewTodoItem(todo.Title, todo.Description)
+	if err != nil {
+		return nil, err
+	}
+	next.Priority = todo.Priority
+	next.DueAt = due
+	next.Project = todo.Project
+	next.Tags = append([]string(nil), todo.Tags...)
+	if todo.HasDue() {
+		next.Recurrence = todo.Recurrence.onDay(todo.DueAt)
+	} else {
+		next.Recurrence = todo.Recurrence.onDay(completedAt)
+	}
+	return next, nil
+}
+
+// Complete marks the TodoItem with the ID complete, and the incomplete TodoItems below it. If it repeats and
+// wasn't already complete, its next occurrence is added to the list and takes over the Recurrence, so that
+// completing it again doesn't repeat it twice. It returns the completed subtasks and the next occurrence, or
+// nil if there is none.
+func (todoList *TodoList) Complete(id string) ([]TodoItem, *TodoItem, error) {
+	todo, err := todoList.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	subtasks := todoList.CompleteSubtasks(todo.ID)
+	wasDone := todo.IsDone
+	todo.Done()
+	if wasDone || todo.Recurrence == RecurrenceNone {
+		todoList.Update(*todo)
+		return subtasks, nil, nil
+	}
+
+	next, err := todo.NextOccurrence()
+	if err != nil {
+		return nil, nil, err
+	}
+	todo.Recurrence = RecurrenceNone
+	todoList.Update(*todo)
+	todoList.Add(*next)
+	if next, err = todoList.Get(next.ID); err != nil {
+		return nil, nil, err
+	}
+	return subtasks, next, nil
+}
@@ -0,0 +1,191 @@
+package todo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Recurrence
+	}{
+		{"daily", "FREQ=DAILY"},
+		{"Weekly", "FREQ=WEEKLY"},
+		{"weekly:thu,mon", "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{"weekly:tuesday,sun", "FREQ=WEEKLY;BYDAY=TU,SU"},
+		{"monthly", "FREQ=MONTHLY"},
+		{"monthly:15", "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"+3d", "FREQ=DAILY;INTERVAL=3;X-FROM=COMPLETION"},
+		{"+2w", "FREQ=WEEKLY;INTERVAL=2;X-FROM=COMPLETION"},
+		{"freq=weekly;interval=2;byday=fr", "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{"FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=31", "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{"none", RecurrenceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRecurrence(tt.input)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"yearly",
+		"daily:2",
+		"weekly:m",
+		"weekly:funday",
+		"monthly:32",
+		"+0d",
+		"+3y",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=MO;X-FROM=COMPLETION",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseRecurrence(input); !errors.Is(err, ErrInvalidRecurrence) {
+				t.Errorf("Expected %v, got %v", ErrInvalidRecurrence, err)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	date := func(day, hour int) time.Time {
+		return time.Date(2026, 10, day, hour, 0, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name        string
+		rule        string
+		due         time.Time
+		completedAt time.Time
+		want        time.Time
+	}{
+		{"Daily", "daily", date(14, 0), date(14, 18), date(15, 0)},
+		{"Daily completed late", "daily", date(10, 0), date(14, 18), date(15, 0)},
+		{"Daily without due date", "daily", time.Time{}, date(14, 18), date(15, 0)},
+		{"Weekly keeps the time of day", "weekly", date(14, 17), date(14, 9), date(21, 17)},
+		{"Weekly completed early", "weekly", date(14, 0), date(12, 9), date(21, 0)},
+		// 2026-10-14 is a Wednesday.
+		{"Weekly on weekdays, later this week", "weekly:mon,thu", date(14, 0), date(14, 9), date(15, 0)},
+		{"Weekly on weekdays, next week", "weekly:mon,thu", date(15, 0), date(15, 9), date(19, 0)},
+		{"Every other week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", date(12, 0), date(12, 9), date(26, 0)},
+		{"Monthly", "monthly", date(14, 0), date(14, 9), time.Date(2026, 11, 14, 0, 0, 0, 0, time.Local)},
+		{"Monthly on a day", "monthly:20", date(14, 0), date(14, 9), date(20, 0)},
+		{"Monthly on a day past the end of the month", "monthly:31", date(31, 0), date(31, 9), time.Date(2026, 11, 30, 0, 0, 0, 0, time.Local)},
+		{"After completion", "+3d", date(10, 9), date(14, 18), date(17, 9)},
+		{"Weeks after completion", "+1w", time.Time{}, date(14, 18), date(21, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			got, err := recurrence.Next(tt.due, tt.completedAt)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	todo := newTodoItem(t, "Water plants", "")
+	todo.Num = 3
+	todo.Priority = "B"
+	todo.Tags = []string{"home"}
+	todo.DueAt = time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+	todo.Recurrence = "FREQ=WEEKLY"
+	todo.Done()
+
+	next, err := todo.NextOccurrence()
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if next.ID == todo.ID || next.Num != 0 || next.IsDone || !next.CompletedAt.IsZero() {
+		t.Errorf("Expected a new incomplete todo, got %+v", next)
+	}
+	if next.Title != todo.Title || next.Priority != todo.Priority || next.Recurrence != todo.Recurrence || len(next.Tags) != 1 {
+		t.Errorf("Expected a copy of %+v, got %+v", todo, next)
+	}
+	if !next.DueAt.After(todo.DueAt) {
+		t.Errorf("Expected after %s, got %s", todo.DueAt, next.DueAt)
+	}
+	if got := Recurrence("FREQ=DAILY;INTERVAL=3;X-FROM=COMPLETION").RRule(); got != "FREQ=DAILY;INTERVAL=3" {
+		t.Errorf("Expected %s, got %s", "FREQ=DAILY;INTERVAL=3", got)
+	}
+}
+
+func TestNextOccurrenceMonthly(t *testing.T) {
+	todo := newTodoItem(t, "Pay rent", "")
+	todo.DueAt = time.Date(2027, 1, 31, 0, 0, 0, 0, time.Local)
+	todo.Recurrence = "FREQ=MONTHLY"
+
+	// Repeating from the end of February goes back to the 31st, rather than staying on the 28th.
+	for _, want := range []time.Time{
+		time.Date(2027, 2, 28, 0, 0, 0, 0, time.Local),
+		time.Date(2027, 3, 31, 0, 0, 0, 0, time.Local),
+		time.Date(2027, 4, 30, 0, 0, 0, 0, time.Local),
+	} {
+		todo.IsDone = true
+		todo.CompletedAt = todo.DueAt
+		next, err := todo.NextOccurrence()
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if !next.DueAt.Equal(want) {
+			t.Errorf("Expected %s, got %s", want, next.DueAt)
+		}
+		todo = next
+	}
+	if todo.Recurrence != "FREQ=MONTHLY;BYMONTHDAY=31" {
+		t.Errorf("Expected %s, got %s", "FREQ=MONTHLY;BYMONTHDAY=31", todo.Recurrence)
+	}
+}
+
+func TestTodoListComplete(t *testing.T) {
+	todoList, err := NewTodoList(NewStore(filepath.Join(t.TempDir(), "todo.json")))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todoList.Add(TodoItem{ID: "w1", Title: "Water plants", DueAt: time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local), Recurrence: "FREQ=WEEKLY"})
+	todoList.Add(TodoItem{ID: "f2", Title: "Fill can", ParentID: "w1"})
+
+	subtasks, next, err := todoList.Complete("1")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(subtasks) != 1 || subtasks[0].ID != "f2" || !subtasks[0].IsDone {
+		t.Errorf("Expected f2 completed, got %v", subtasks)
+	}
+	if next == nil || next.Num != 3 || next.Recurrence != "FREQ=WEEKLY" || next.IsDone {
+		t.Fatalf("Expected an incomplete weekly todo numbered 3, got %+v", next)
+	}
+	todo, err := todoList.Get("w1")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if !todo.IsDone || todo.Recurrence != RecurrenceNone {
+		t.Errorf("Expected a completed todo that no longer repeats, got %+v", todo)
+	}
+
+	// Completing it again doesn't repeat it twice.
+	if _, next, err := todoList.Complete("w1"); err != nil || next != nil {
+		t.Errorf("Expected no next occurrence, got %v, %v", next, err)
+	}
+	if len(todoList.List()) != 3 {
+		t.Errorf("Expected %d, got %d", 3, len(todoList.List()))
+	}
+}
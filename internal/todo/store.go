@@ -0,0 +1,51 @@
+package todo
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Store is an interface that defines the methods to save and load todos from disk.
+type Store interface {
+	Save(todos []TodoItem) error
+	Load() ([]TodoItem, error)
+}
+
+// IncrementalStore is a Store that can persist only the todos changed since the last save.
+// SaveChanges removes the todos marked as deleted and inserts or updates the rest.
+type IncrementalStore interface {
+	Store
+	SaveChanges(todos []TodoItem) error
+}
+
+// SequenceStore is a Store that persists the next number to assign to a todo, so that the numbers of deleted
+// todos are never reused. NextNum is the value read by the last Load, and SetNextNum sets the value written
+// by the next save.
+type SequenceStore interface {
+	Store
+	NextNum() int
+	SetNextNum(n int)
+}
+
+// RecoveringStore is a Store that loads the backup of its file when the file can't be loaded. Recovered
+// returns an error wrapping ErrRecovered if the last Load did so, and nil otherwise.
+type RecoveringStore interface {
+	Store
+	Recovered() error
+}
+
+// NewStore creates the store matching the extension of the file at path.
+// `.json` files use TodoListJsonStore, `.db`, `.sqlite` and `.sqlite3` files use TodoListSqliteStore,
+// `.txt` files use TodoListTodoTxtStore, and anything else uses TodoListCsvStore.
+func NewStore(path string) Store {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return NewTodoListJsonStore(path)
+	case ".db", ".sqlite", ".sqlite3":
+		return NewTodoListSqliteStore(path)
+	case ".txt":
+		return NewTodoListTodoTxtStore(path)
+	default:
+		return NewTodoListCsvStore(path)
+	}
+}
@@ -0,0 +1,192 @@
+package todo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrConflict is returned when a store file was changed by someone else after it was loaded.
+	ErrConflict = errors.New("Todo file was modified by another process")
+	// ErrRecovered is returned by the Recovered method of a store whose file couldn't be loaded, so that its
+	// backup was loaded instead.
+	ErrRecovered = errors.New("Todo file could not be loaded, its backup was loaded instead")
+)
+
+// fileVersion remembers the contents of a store file as it was loaded, so saving can detect that another
+// process wrote to the file in the meantime instead of silently overwriting its changes.
+type fileVersion struct {
+	known bool
+	sum   [sha256.Size]byte
+	// recovered is set when the file could not be loaded and its backup was loaded instead, in which case the
+	// file must not replace the backup on the next save.
+	recovered error
+}
+
+// set records data as the current contents of the file.
+func (v *fileVersion) set(data []byte) {
+	v.known = true
+	v.sum = sha256.Sum256(data)
+	v.recovered = nil
+}
+
+// check returns ErrConflict if the file at path no longer has the recorded contents.
+func (v *fileVersion) check(path string) error {
+	if !v.known {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if sha256.Sum256(data) != v.sum {
+		return fmt.Errorf("%w: %s changed since it was loaded, run the command again", ErrConflict, path)
+	}
+	return nil
+}
+
+// backupPath returns the path of the backup kept for a store file.
+func backupPath(path string) string {
+	return path + ".bak"
+}
+
+// saveFile checks that the file at path is still at version, replaces it with data, and records data as
+// the new version.
+func saveFile(path string, data []byte, version *fileVersion) error {
+	if err := version.check(path); err != nil {
+		return err
+	}
+	if err := writeFile(path, data, version.recovered == nil); err != nil {
+		return err
+	}
+	version.set(data)
+	return nil
+}
+
+// writeFile replaces the file at path with data without ever leaving a partially written file behind.
+// The data is written to a temporary file in the same directory, synced, and renamed over path.
+// If backup is set, the previous version of the file, if any, is kept as its backup. The file keeps its
+// permissions, or is created with 0644.
+func writeFile(path string, data []byte, backup bool) error {
+	dir := filepath.Dir(path)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, fileMode(path)); err != nil {
+		return err
+	}
+
+	if backup {
+		if err := backupFile(path); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+
+	return nil
+}
+
+// backupFile rotates the current version of the file at path into its backup.
+func backupFile(path string) error {
+	bakPath := backupPath(path)
+	if err := os.Remove(bakPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// A hard link keeps the old contents reachable once the new file is renamed over path.
+	err := os.Link(path, bakPath)
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	// Fall back to copying on file systems without hard links.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bakPath, data, fileMode(path))
+}
+
+// fileMode returns the permissions of the file at path, or 0644 if it doesn't exist.
+func fileMode(path string) fs.FileMode {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0644
+	}
+	return info.Mode().Perm()
+}
+
+// syncDir flushes a directory entry change such as a rename to disk. Not every platform supports it, so
+// errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// corruptPath returns the path a copy of a store file that could not be parsed is kept at.
+func corruptPath(path string) string {
+	return path + ".corrupt"
+}
+
+// loadFile reads the file at path, records its version, and decodes it with parse. A missing file is
+// decoded as empty. If the file cannot be read or parsed, its backup is tried instead, and the original
+// error is returned if the backup fails as well. When the backup is loaded, version records ErrRecovered, and
+// a file that could be read but not parsed is copied to its corrupt path before the next save replaces it.
+func loadFile(path string, version *fileVersion, parse func(data []byte) ([]TodoItem, error)) ([]TodoItem, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = nil, nil
+	}
+	read := err == nil
+	if read {
+		version.set(data)
+		var todos []TodoItem
+		if todos, err = parse(data); err == nil {
+			return todos, nil
+		}
+	}
+
+	bakData, bakErr := os.ReadFile(backupPath(path))
+	if bakErr != nil {
+		return nil, err
+	}
+	todos, bakErr := parse(bakData)
+	if bakErr != nil {
+		return nil, err
+	}
+	if !read {
+		version.recovered = fmt.Errorf("%w. %s: %s", ErrRecovered, path, err)
+		return todos, nil
+	}
+	if copyErr := os.WriteFile(corruptPath(path), data, fileMode(path)); copyErr != nil {
+		return nil, err
+	}
+	version.recovered = fmt.Errorf("%w. %s: %s. A copy was kept at %s", ErrRecovered, path, err, corruptPath(path))
+	return todos, nil
+}
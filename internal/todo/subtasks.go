@@ -0,0 +1,114 @@
+package todo
+
+import "fmt"
+
+// Progress counts the todos below a todo, its subtasks and theirs, and how many of them are done.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// String formats the progress such as `3/5`.
+func (p Progress) String() string {
+	return fmt.Sprintf("%d/%d", p.Done, p.Total)
+}
+
+// Children returns the TodoItems in the list that are subtasks of the TodoItem with the ID.
+func (todoList *TodoList) Children(id string) []TodoItem {
+	children := []TodoItem{}
+	for _, todo := range todoList.Todos {
+		if todo.ParentID == id {
+			children = append(children, todo)
+		}
+	}
+	return children
+}
+
+// Progress returns the progress of the TodoItems in the list that have subtasks, keyed by ID.
+func (todoList *TodoList) Progress() map[string]Progress {
+	progress := map[string]Progress{}
+	for _, todo := range todoList.Todos {
+		below := descendants(todoList.Todos, todo.ID)
+		if len(below) == 0 {
+			continue
+		}
+		p := Progress{Total: len(below)}
+		for _, i := range below {
+			if todoList.Todos[i].IsDone {
+				p.Done++
+			}
+		}
+		progress[todo.ID] = p
+	}
+	return progress
+}
+
+// CompleteSubtasks marks the incomplete TodoItems below the TodoItem with the ID complete, and returns them.
+func (todoList *TodoList) CompleteSubtasks(id string) []TodoItem {
+	completed := []TodoItem{}
+	for _, i := range descendants(todoList.Todos, id) {
+		todo := todoList.Todos[i]
+		if todo.IsDone {
+			continue
+		}
+		todo.Done()
+		todoList.Update(todo)
+		completed = append(completed, todoList.Todos[i])
+	}
+	return completed
+}
+
+// descendants returns the indexes of the todos below the todo with the ID, depth first. A todo that is its own
+// ancestor, as only a hand edited file can have, is not visited twice.
+func descendants(todos []TodoItem, id string) []int {
+	indexes := []int{}
+	visited := map[string]bool{id: true}
+	var visit func(id string)
+	visit = func(id string) {
+		for i, todo := range todos {
+			if todo.ParentID == id && !visited[todo.ID] {
+				visited[todo.ID] = true
+				indexes = append(indexes, i)
+				visit(todo.ID)
+			}
+		}
+	}
+	visit(id)
+	return indexes
+}
+
+// Tree orders todos so that each todo is followed by its subtasks, keeping the order of siblings, and returns
+// the depth of each todo keyed by ID. Todos whose parent is not among todos are at the top level.
+func Tree(todos []TodoItem) ([]TodoItem, map[string]int) {
+	present := make(map[string]bool, len(todos))
+	for _, todo := range todos {
+		present[todo.ID] = true
+	}
+
+	ordered := make([]TodoItem, 0, len(todos))
+	depths := make(map[string]int, len(todos))
+	visited := make(map[string]bool, len(todos))
+	var visit func(i, depth int)
+	visit = func(i, depth int) {
+		visited[todos[i].ID] = true
+		ordered = append(ordered, todos[i])
+		depths[todos[i].ID] = depth
+		for j, todo := range todos {
+			if todo.ParentID == todos[i].ID && !visited[todo.ID] {
+				visit(j, depth+1)
+			}
+		}
+	}
+	for i, todo := range todos {
+		if !present[todo.ParentID] && !visited[todo.ID] {
+			visit(i, 0)
+		}
+	}
+	// Todos in a cycle of parents have no top level ancestor.
+	for i, todo := range todos {
+		if !visited[todo.ID] {
+			visit(i, 0)
+		}
+	}
+	return ordered, depths
+}
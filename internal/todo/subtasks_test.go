@@ -0,0 +1,115 @@
+package todo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeTree returns a list with the tree:
+//
+//	1 Plan party
+//	  2 Book venue
+//	    3 Compare prices
+//	  4 Send invites
+//	5 Buy milk
+func makeTree(t *testing.T, path string) *TodoList {
+	t.Helper()
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	for _, todo := range []TodoItem{
+		{ID: "p1", Title: "Plan party"},
+		{ID: "v2", Title: "Book venue", ParentID: "p1"},
+		{ID: "c3", Title: "Compare prices", ParentID: "v2"},
+		{ID: "i4", Title: "Send invites", ParentID: "p1"},
+		{ID: "m5", Title: "Buy milk"},
+	} {
+		todoList.Add(todo)
+	}
+	return todoList
+}
+
+func ids(todos []TodoItem) string {
+	ids := []string{}
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestTree(t *testing.T) {
+	todoList := makeTree(t, "todos.csv")
+
+	// Siblings keep their order, and subtasks follow their parent.
+	todos := []TodoItem{todoList.Todos[4], todoList.Todos[3], todoList.Todos[2], todoList.Todos[1], todoList.Todos[0]}
+	ordered, depths := Tree(todos)
+	if got := ids(ordered); got != "m5 p1 i4 v2 c3" {
+		t.Errorf("Expected %s, got %s", "m5 p1 i4 v2 c3", got)
+	}
+	want := map[string]int{"m5": 0, "p1": 0, "i4": 1, "v2": 1, "c3": 2}
+	for id, depth := range want {
+		if depths[id] != depth {
+			t.Errorf("Expected depth %d for %s, got %d", depth, id, depths[id])
+		}
+	}
+
+	// Subtasks whose parent is filtered out are at the top level.
+	ordered, depths = Tree(todoList.Todos[1:3])
+	if got := ids(ordered); got != "v2 c3" || depths["v2"] != 0 || depths["c3"] != 1 {
+		t.Errorf("Expected v2 c3 from the top level, got %s %v", got, depths)
+	}
+
+	// A cycle of parents doesn't lose todos.
+	cycle := []TodoItem{{ID: "a", ParentID: "b"}, {ID: "b", ParentID: "a"}}
+	if ordered, _ := Tree(cycle); len(ordered) != 2 {
+		t.Errorf("Expected 2 todos, got %d", len(ordered))
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+	todoList := makeTree(t, path)
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if got := ids(todoList.Children("p1")); got != "v2 i4" {
+		t.Errorf("Expected %s, got %s", "v2 i4", got)
+	}
+
+	// Progress counts all the todos below a todo.
+	todo, _ := todoList.Get("c3")
+	todo.Done()
+	todoList.Update(*todo)
+	progress := todoList.Progress()
+	if len(progress) != 2 || progress["p1"].String() != "1/3" || progress["v2"].String() != "1/1" {
+		t.Errorf("Expected p1 1/3 and v2 1/1, got %v", progress)
+	}
+
+	// Completing a todo completes its incomplete subtasks.
+	if completed := todoList.CompleteSubtasks("p1"); ids(completed) != "v2 i4" {
+		t.Errorf("Expected %s, got %s", "v2 i4", ids(completed))
+	}
+	if progress := todoList.Progress(); progress["p1"].String() != "3/3" {
+		t.Errorf("Expected 3/3, got %s", progress["p1"])
+	}
+
+	// Deleting a todo deletes its subtasks, and restoring it restores them.
+	if err := todoList.Delete("v2"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if got := ids(todoList.Trash()); got != "v2 c3" {
+		t.Errorf("Expected %s, got %s", "v2 c3", got)
+	}
+	if _, err := todoList.Restore("v2"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if got := ids(todoList.List()); got != "p1 v2 c3 i4 m5" {
+		t.Errorf("Expected %s, got %s", "p1 v2 c3 i4 m5", got)
+	}
+}
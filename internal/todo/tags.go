@@ -0,0 +1,94 @@
+package todo
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// HasTag reports whether the todo has the tag, ignoring case.
+func (todo *TodoItem) HasTag(tag string) bool {
+	for _, t := range todo.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds the tag unless the todo already has it.
+func (todo *TodoItem) AddTag(tag string) {
+	if !todo.HasTag(tag) {
+		todo.Tags = append(todo.Tags, tag)
+	}
+}
+
+// RemoveTag removes the tag, ignoring case.
+func (todo *TodoItem) RemoveTag(tag string) {
+	tags := []string{}
+	for _, t := range todo.Tags {
+		if !strings.EqualFold(t, tag) {
+			tags = append(tags, t)
+		}
+	}
+	todo.Tags = tags
+}
+
+// InProject reports whether the todo belongs to the project, ignoring case.
+func (todo *TodoItem) InProject(project string) bool {
+	return todo.Project != "" && strings.EqualFold(todo.Project, project)
+}
+
+// ParsedTitle is a title with its `+project` and `@tag` tokens split out.
+type ParsedTitle struct {
+	Title   string
+	Project string
+	Tags    []string
+	// OtherProjects are the projects of the `+project` tokens left in Title, since a todo has one project.
+	OtherProjects []string
+}
+
+// titleWord matches a word of a title with the whitespace that follows it.
+var titleWord = regexp.MustCompile(`\S+\s*`)
+
+// ParseTitle splits the `+project` and `@tag` tokens out of a title, such as `Call Bob +work @phone`. The first
+// project token names the project, and is removed with the tokens repeating it. The tokens of other projects
+// are left in the title. Each token is removed with the whitespace that follows it, and the spacing of the
+// other words is kept.
+func ParseTitle(title string) ParsedTitle {
+	parsed := ParsedTitle{}
+	var b strings.Builder
+	for _, chunk := range titleWord.FindAllString(title, -1) {
+		token := strings.TrimRightFunc(chunk, unicode.IsSpace)
+		if project, ok := ProjectToken(token); ok && (parsed.Project == "" || strings.EqualFold(project, parsed.Project)) {
+			if parsed.Project == "" {
+				parsed.Project = project
+			}
+		} else if ok {
+			parsed.OtherProjects = append(parsed.OtherProjects, project)
+			b.WriteString(chunk)
+		} else if tag, ok := TagToken(token); ok {
+			parsed.Tags = append(parsed.Tags, tag)
+		} else {
+			b.WriteString(chunk)
+		}
+	}
+	parsed.Title = strings.TrimRightFunc(b.String(), unicode.IsSpace)
+	return parsed
+}
+
+// ProjectToken returns the project named by a `+project` token.
+func ProjectToken(token string) (string, bool) {
+	if len(token) > 1 && token[0] == '+' {
+		return token[1:], true
+	}
+	return "", false
+}
+
+// TagToken returns the tag named by an `@tag` token.
+func TagToken(token string) (string, bool) {
+	if len(token) > 1 && token[0] == '@' {
+		return token[1:], true
+	}
+	return "", false
+}
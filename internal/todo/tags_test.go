@@ -0,0 +1,30 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		title   string
+		want    string
+		project string
+		tags    string
+		others  string
+	}{
+		{"Call Bob +work @phone @errand", "Call Bob", "work", "phone errand", ""},
+		{"Call  Bob +work at 10:00 @phone", "Call  Bob at 10:00", "work", "phone", ""},
+		{"+work Deploy +Work app", "Deploy app", "work", "", ""},
+		{"Call mom +family +phone", "Call mom +phone", "family", "", "phone"},
+		{"Vote + or @", "Vote + or @", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := ParseTitle(tt.title)
+			if got.Title != tt.want || got.Project != tt.project || strings.Join(got.Tags, " ") != tt.tags || strings.Join(got.OtherProjects, " ") != tt.others {
+				t.Errorf("Expected %q +%s @%s and others %q, got %+v", tt.want, tt.project, tt.tags, tt.others, got)
+			}
+		})
+	}
+}
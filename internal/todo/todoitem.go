@@ -1,7 +1,8 @@
 package todo
 
 import (
-	"crypto/sha1"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"strconv"
@@ -9,17 +10,30 @@ import (
 )
 
 type TodoItem struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	IsDone      bool      `json:"is_done"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	dirty       bool
-	deleted     bool
+	ID           string     `json:"id"`
+	Num          int        `json:"num"`
+	ParentID     string     `json:"parent_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	IsDone       bool       `json:"is_done"`
+	Priority     Priority   `json:"priority"`
+	DueAt        time.Time  `json:"due_at"`
+	Recurrence   Recurrence `json:"recurrence"`
+	Project      string     `json:"project"`
+	Tags         []string   `json:"tags"`
+	Dependencies []string   `json:"depends_on"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CompletedAt  time.Time  `json:"completed_at"`
+	DeletedAt    time.Time  `json:"deleted_at"`
+	dirty        bool
+	deleted      bool
+	// archived is set on a todo moved between the list and the archive since the last flush.
+	archived bool
 }
 
-func NewTodoItem(title, desc string) *TodoItem {
+// NewTodoItem creates a new TodoItem with a new ID. It returns an error if no ID could be generated.
+func NewTodoItem(title, desc string) (*TodoItem, error) {
 	todo := &TodoItem{
 		ID:          "",
 		Title:       title,
@@ -30,8 +44,10 @@ func NewTodoItem(title, desc string) *TodoItem {
 		dirty:       false,
 		deleted:     false,
 	}
-	todo.assignID()
-	return todo
+	if err := todo.assignID(); err != nil {
+		return nil, err
+	}
+	return todo, nil
 }
 
 func NewTodoItemFromStrings(id, title, desc, isDone, createdAt, updatedAt string) (*TodoItem, error) {
@@ -60,19 +76,41 @@ func NewTodoItemFromStrings(id, title, desc, isDone, createdAt, updatedAt string
 	}, nil
 }
 
-// assignID assigns a unique ID to the TodoItem.
-func (todo *TodoItem) assignID() {
-	now := time.Now()
-	hasher := sha1.New()
-	hasher.Write([]byte(todo.Title + todo.Description + now.Format("2006-01-02 15:04:05 MST")))
-	hashSum := hasher.Sum(nil)
-	todo.ID = hex.EncodeToString(hashSum)[:16]
+// assignID assigns a random 64-bit ID to the TodoItem, in hex like the IDs of earlier versions. Unlike a hash
+// of the contents, it doesn't collide when the same todo is added twice. IDs don't start with a timestamp to
+// sort by creation: todos added around the same time would share long prefixes, and ShortIDs would have to
+// show many more characters to tell them apart. Todos sort by CreatedAt or Num instead.
+func (todo *TodoItem) assignID() error {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("%w: Error generating todo ID", err)
+	}
+	todo.ID = hex.EncodeToString(b)
+	return nil
+}
+
+// DerivedID returns an ID in the same form as assigned IDs, derived from key, for todos read from files that
+// don't have IDs of their own. The same key always gives the same ID.
+func DerivedID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
 }
 
+// IsDeleted reports whether the todo is in the trash.
+func (todo *TodoItem) IsDeleted() bool {
+	return !todo.DeletedAt.IsZero()
+}
+
+// Done marks the todo complete, recording when unless it already was.
 func (todo *TodoItem) Done() {
+	if !todo.IsDone {
+		todo.CompletedAt = time.Now()
+	}
 	todo.IsDone = true
 }
 
+// Undone marks the todo incomplete, clearing when it was completed.
 func (todo *TodoItem) Undone() {
 	todo.IsDone = false
+	todo.CompletedAt = time.Time{}
 }
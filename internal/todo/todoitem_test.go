@@ -1,10 +1,44 @@
 package todo
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
+
+// newTodoItem returns a new TodoItem, failing the test if no ID could be generated.
+func newTodoItem(t *testing.T, title, desc string) *TodoItem {
+	t.Helper()
+	todo, err := NewTodoItem(title, desc)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	return todo
+}
+
+func TestAssignID(t *testing.T) {
+	// The same todo added many times in the same second gets distinct IDs.
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		todo, err := NewTodoItem("Task", "Added by a script")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if len(todo.ID) != 16 {
+			t.Errorf("Expected %d, got %d", 16, len(todo.ID))
+		}
+		if seen[todo.ID] {
+			t.Fatalf("Expected unique ID, got %s twice", todo.ID)
+		}
+		seen[todo.ID] = true
+	}
+}
 
 func TestTodoItem(t *testing.T) {
 	t.Run("NewTodoItem", func(t *testing.T) {
-		todo := NewTodoItem("Task 1", "Created for test")
+		todo, err := NewTodoItem("Task 1", "Created for test")
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
 		want := TodoItem{
 			ID:          todo.ID,
 			Title:       "Task 1",
@@ -65,7 +99,7 @@ func TestTodoItem(t *testing.T) {
 	})
 
 	t.Run("Mark done", func(t *testing.T) {
-		todo := NewTodoItem("Task 1", "Created for test")
+		todo := newTodoItem(t, "Task 1", "Created for test")
 		todo.Done()
 		if todo.IsDone != true {
 			t.Errorf("Expected true, got %t", todo.IsDone)
@@ -73,7 +107,7 @@ func TestTodoItem(t *testing.T) {
 	})
 
 	t.Run("Mark undone", func(t *testing.T) {
-		todo := NewTodoItem("Task 1", "Created for test")
+		todo := newTodoItem(t, "Task 1", "Created for test")
 		todo.Done()
 		if todo.IsDone != true {
 			t.Errorf("Expected true, got %t", todo.IsDone)
@@ -84,3 +118,95 @@ func TestTodoItem(t *testing.T) {
 		}
 	})
 }
+
+func TestPriority(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Priority
+	}{
+		{"A", "A"},
+		{"z", "Z"},
+		{"high", "A"},
+		{"Medium", "B"},
+		{"low", "C"},
+		{"none", PriorityNone},
+	}
+	for _, tt := range tests {
+		priority, err := ParsePriority(tt.input)
+		if err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+		if priority != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, priority)
+		}
+	}
+
+	if _, err := ParsePriority("AA"); err == nil {
+		t.Errorf("Expected error, got nil")
+	}
+
+	todos := []TodoItem{
+		{ID: "1", Priority: PriorityNone},
+		{ID: "2", Priority: "C"},
+		{ID: "3", Priority: "A"},
+		{ID: "4", Priority: PriorityNone},
+		{ID: "5", Priority: "C"},
+	}
+	SortByPriority(todos)
+	for i, id := range []string{"3", "2", "5", "1", "4"} {
+		if todos[i].ID != id {
+			t.Errorf("Expected %s, got %s", id, todos[i].ID)
+		}
+	}
+}
+
+func TestTodoItemDue(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		due      time.Time
+		done     bool
+		overdue  bool
+		dueToday bool
+	}{
+		{"No due date", time.Time{}, false, false, false},
+		{"Due yesterday", time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local), false, true, false},
+		{"Due today", time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local), false, false, true},
+		{"Due earlier today", time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local), false, true, false},
+		{"Due later today", time.Date(2026, 10, 14, 17, 0, 0, 0, time.Local), false, false, true},
+		{"Due tomorrow", time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local), false, false, false},
+		{"Done", time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local), true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := TodoItem{DueAt: tt.due, IsDone: tt.done}
+			if todo.IsOverdue(now) != tt.overdue {
+				t.Errorf("Expected %t, got %t", tt.overdue, todo.IsOverdue(now))
+			}
+			if todo.IsDueToday(now) != tt.dueToday {
+				t.Errorf("Expected %t, got %t", tt.dueToday, todo.IsDueToday(now))
+			}
+		})
+	}
+}
+
+func TestTodoItemTags(t *testing.T) {
+	todo := newTodoItem(t, "Task 1", "Created for test")
+	todo.AddTag("phone")
+	todo.AddTag("Phone")
+	todo.AddTag("work")
+	if len(todo.Tags) != 2 {
+		t.Errorf("Expected %d, got %d", 2, len(todo.Tags))
+	}
+	if !todo.HasTag("PHONE") {
+		t.Errorf("Expected true, got false")
+	}
+	todo.RemoveTag("phone")
+	if todo.HasTag("phone") {
+		t.Errorf("Expected false, got true")
+	}
+	if len(todo.Tags) != 1 {
+		t.Errorf("Expected %d, got %d", 1, len(todo.Tags))
+	}
+}
@@ -1,12 +1,44 @@
 package todo
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinIDPrefixLength is the length ShortIDs shortens IDs to, unless more characters are needed to tell them apart.
+const MinIDPrefixLength = 4
+
+var (
+	ErrNotFound    = errors.New("Todo not found")
+	ErrAmbiguousID = errors.New("Ambiguous ID")
+	ErrDuplicateID = errors.New("Duplicate ID")
+)
+
 type TodoList struct {
-	Todos    []TodoItem
+	Todos []TodoItem
+	// Trashed holds the deleted todos until they are restored or purged.
+	Trashed  []TodoItem
 	modified bool
+	removed  []TodoItem
 	store    Store
+	// nextNum is the number assigned to the next todo added.
+	nextNum int
+	// journal records the flushed changes, if set. snapshot holds the todos as last loaded or flushed, keyed
+	// by ID, to tell what changed.
+	journal  *Journal
+	snapshot map[string]TodoItem
+	// archive holds the archived todos, loaded from archiveStore when first needed.
+	archiveStore Store
+	archive      *TodoList
 }
 
-// NewTodoList creates a new TodoList.
+// NewTodoList creates a new TodoList. It returns ErrDuplicateID if several todos loaded from the store share an
+// ID, as files written by earlier versions can; RepairIDs fixes them.
 func NewTodoList(store Store) (*TodoList, error) {
 	list := &TodoList{
 		modified: false,
@@ -16,37 +48,145 @@ func NewTodoList(store Store) (*TodoList, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ids := DuplicateIDs(todos); len(ids) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateID, strings.Join(ids, ", "))
+	}
 	list.setTodos(todos)
+	list.assignNums()
+	list.takeSnapshot()
 	return list, nil
 }
 
-// List returns all TodoItems.
+// Recovered returns an error wrapping ErrRecovered if the store's file couldn't be loaded and its backup was
+// loaded instead, and nil otherwise.
+func (todoList *TodoList) Recovered() error {
+	if store, ok := todoList.store.(RecoveringStore); ok {
+		return store.Recovered()
+	}
+	return nil
+}
+
+// List returns all TodoItems, except those in the trash.
 func (todoList *TodoList) List() []TodoItem {
 	return todoList.Todos
 }
 
-// Get returns a TodoItem by ID.
-func (todoList *TodoList) Get(id string) *TodoItem {
-	idLen := len(id)
-	for i := range todoList.Todos {
-		todo := &todoList.Todos[i]
-		if todo.ID[:idLen] == id {
-			return todo
+// Get returns the TodoItem whose ID is id, or else the one numbered id, or else the only one whose ID starts
+// with id. It returns ErrNotFound if no ID starts with id, and ErrAmbiguousID listing the candidates if several do.
+// A number shorter than MinIDPrefixLength is not matched as a prefix. Todos in the trash are not matched.
+func (todoList *TodoList) Get(id string) (*TodoItem, error) {
+	i, err := find(todoList.Todos, id)
+	if err != nil {
+		return nil, err
+	}
+	return &todoList.Todos[i], nil
+}
+
+// find returns the index of the TodoItem among todos matching id, as described by Get.
+func find(todos []TodoItem, id string) (int, error) {
+	if id == "" {
+		return -1, fmt.Errorf("%w: empty ID", ErrNotFound)
+	}
+	for i, todo := range todos {
+		if todo.ID == id {
+			return i, nil
 		}
 	}
-	return nil
+	if num, err := strconv.Atoi(id); err == nil {
+		for i, todo := range todos {
+			if num > 0 && todo.Num == num {
+				return i, nil
+			}
+		}
+		// A short number names a todo by number, so one that was deleted is not found rather than resolved
+		// as the prefix of another todo's ID.
+		if len(id) < MinIDPrefixLength {
+			return -1, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+	}
+	matches := []int{}
+	for i, todo := range todos {
+		if strings.HasPrefix(todo.ID, id) {
+			matches = append(matches, i)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		// A prefix longer than an ID, as when an ID is mistyped with extra characters.
+		for _, todo := range todos {
+			if strings.HasPrefix(id, todo.ID) {
+				return -1, fmt.Errorf("%w: %s is longer than ID %s", ErrNotFound, id, todo.ID)
+			}
+		}
+		return -1, fmt.Errorf("%w: %s", ErrNotFound, id)
+	default:
+		candidates := []string{}
+		for _, i := range matches {
+			candidates = append(candidates, fmt.Sprintf("%s %s", todos[i].ID, todos[i].Title))
+		}
+		return -1, fmt.Errorf("%w: %s matches %s", ErrAmbiguousID, id, strings.Join(candidates, ", "))
+	}
+}
+
+// ShortIDs returns the shortest prefix of each ID, of at least MinIDPrefixLength characters, that no other ID
+// in the list or the trash starts with, keyed by ID.
+func (todoList *TodoList) ShortIDs() map[string]string {
+	ids := []string{}
+	for _, todo := range todoList.all() {
+		ids = append(ids, todo.ID)
+	}
+	sort.Strings(ids)
+
+	// Sorted, the ID sharing the longest prefix with an ID is one of its neighbours.
+	short := make(map[string]string, len(ids))
+	for i, id := range ids {
+		length := MinIDPrefixLength
+		if i > 0 {
+			length = max(length, commonPrefixLength(id, ids[i-1])+1)
+		}
+		if i < len(ids)-1 {
+			length = max(length, commonPrefixLength(id, ids[i+1])+1)
+		}
+		short[id] = id[:min(length, len(id))]
+	}
+	return short
+}
+
+// commonPrefixLength returns the length of the longest common prefix of a and b.
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
 }
 
-// Add appends a TodoItem to the list.
+// Add appends a TodoItem to the list, numbering it if it has no number yet.
 func (todoList *TodoList) Add(todo TodoItem) {
+	if todo.Num == 0 {
+		todo.Num = todoList.nextNum
+		todoList.nextNum++
+	} else {
+		todoList.nextNum = max(todoList.nextNum, todo.Num+1)
+	}
+	todo.dirty = true
 	todoList.Todos = append(todoList.Todos, todo)
 	todoList.modified = true
 }
 
-// Update updates a TodoItem in the list that matches the ID.
+// Update updates a TodoItem in the list that matches the ID. The number of the TodoItem is kept if todo has none.
+// UpdatedAt is set to now, or kept as last flushed if the TodoItem is back to how it was.
 func (todoList *TodoList) Update(todo TodoItem) {
 	for i, _todo := range todoList.Todos {
 		if _todo.ID == todo.ID {
+			if todo.Num == 0 {
+				todo.Num = _todo.Num
+			}
+			todo.UpdatedAt = todoList.updatedAt(todo)
+			todo.dirty = true
 			todoList.Todos[i] = todo
 			todoList.modified = true
 			break
@@ -54,33 +194,255 @@ func (todoList *TodoList) Update(todo TodoItem) {
 	}
 }
 
-// Delete removes a TodoItem from the list that matches the ID.
-func (todoList *TodoList) Delete(id string) {
-	idLen := len(id)
-	for i := range todoList.Todos {
-		todo := &todoList.Todos[i]
-		if todo.ID[:idLen] == id {
-			todoList.Todos = append(todoList.Todos[:i], todoList.Todos[i+1:]...)
-			todoList.modified = true
-			break
+// Delete moves the TodoItem matching id, as described by Get, and its subtasks to the trash.
+func (todoList *TodoList) Delete(id string) error {
+	i, err := find(todoList.Todos, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ids := []string{todoList.Todos[i].ID}
+	for _, j := range descendants(todoList.Todos, ids[0]) {
+		ids = append(ids, todoList.Todos[j].ID)
+	}
+	for _, id := range ids {
+		i, _ := find(todoList.Todos, id)
+		todo := todoList.Todos[i]
+		todo.DeletedAt = now
+		todo.UpdatedAt = now
+		todo.dirty = true
+		todoList.Todos = append(todoList.Todos[:i], todoList.Todos[i+1:]...)
+		todoList.Trashed = append(todoList.Trashed, todo)
+	}
+	todoList.modified = true
+	return nil
+}
+
+// Trash returns the TodoItems in the trash.
+func (todoList *TodoList) Trash() []TodoItem {
+	return todoList.Trashed
+}
+
+// Restore moves the TodoItem in the trash matching id, as described by Get, and its subtasks in the trash back
+// to the list.
+func (todoList *TodoList) Restore(id string) (*TodoItem, error) {
+	i, err := find(todoList.Trashed, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{todoList.Trashed[i].ID}
+	for _, j := range descendants(todoList.Trashed, ids[0]) {
+		ids = append(ids, todoList.Trashed[j].ID)
+	}
+	for _, id := range ids {
+		i, _ := find(todoList.Trashed, id)
+		todo := todoList.Trashed[i]
+		todo.DeletedAt = time.Time{}
+		todo.UpdatedAt = time.Now()
+		todo.dirty = true
+		todoList.Trashed = append(todoList.Trashed[:i], todoList.Trashed[i+1:]...)
+		insertByNum(&todoList.Todos, todo)
+	}
+	todoList.modified = true
+	return todoList.Get(ids[0])
+}
+
+// updatedAt returns when the todo was last updated: now if it differs from how it was last flushed, not
+// counting UpdatedAt itself, or else as last flushed.
+func (todoList *TodoList) updatedAt(todo TodoItem) time.Time {
+	before, ok := todoList.snapshot[todo.ID]
+	if !ok {
+		return time.Now()
+	}
+	after := cloneTodo(todo)
+	after.UpdatedAt = before.UpdatedAt
+	if !reflect.DeepEqual(before, after) {
+		return time.Now()
+	}
+	return before.UpdatedAt
+}
+
+// insertByNum inserts the todo into todos before the first todo with a greater number, which puts it back in
+// its place when todos are in the order they were added. It returns the index of the todo.
+func insertByNum(todos *[]TodoItem, todo TodoItem) int {
+	i := sort.Search(len(*todos), func(i int) bool { return (*todos)[i].Num > todo.Num })
+	*todos = append(*todos, TodoItem{})
+	copy((*todos)[i+1:], (*todos)[i:])
+	(*todos)[i] = todo
+	return i
+}
+
+// Purge permanently removes the TodoItems moved to the trash at or before the cutoff, and returns them.
+func (todoList *TodoList) Purge(cutoff time.Time) []TodoItem {
+	purged := []TodoItem{}
+	for i := 0; i < len(todoList.Trashed); {
+		if !todoList.Trashed[i].DeletedAt.After(cutoff) {
+			purged = append(purged, todoList.Trashed[i])
+			todoList.remove(&todoList.Trashed, i)
+			continue
 		}
+		i++
 	}
+	return purged
 }
 
-// Flush writes to the store if the todolist was modified.
+// remove permanently removes the TodoItem at index i of todos, which is the list or the trash.
+func (todoList *TodoList) remove(todos *[]TodoItem, i int) {
+	removed := (*todos)[i]
+	removed.deleted = true
+	todoList.removed = append(todoList.removed, removed)
+	*todos = append((*todos)[:i], (*todos)[i+1:]...)
+	todoList.modified = true
+}
+
+// all returns the TodoItems in the list followed by those in the trash.
+func (todoList *TodoList) all() []TodoItem {
+	return append(append([]TodoItem{}, todoList.Todos...), todoList.Trashed...)
+}
+
+// Flush writes to the store if the todolist was modified, and records the changes in the journal if one is used.
+// Stores implementing IncrementalStore only receive the items changed since the last flush.
 func (todoList *TodoList) Flush() error {
+	return todoList.flush(true)
+}
+
+// FlushUnrecorded writes to the store like Flush, but leaves the changes out of the journal, so undo and redo
+// skip over them. It is meant for housekeeping done before each command, such as purging the trash.
+func (todoList *TodoList) FlushUnrecorded() error {
+	return todoList.flush(false)
+}
+
+// flush implements Flush, recording the changes in the journal only if record is set.
+func (todoList *TodoList) flush(record bool) error {
+	// The archive is written first, so a failure doesn't lose the todos moved to it.
+	if todoList.archive != nil {
+		if err := todoList.archive.flush(false); err != nil {
+			return err
+		}
+	}
 	if !todoList.modified {
 		return nil
 	}
+	entry := todoList.journalEntry()
+
+	if store, ok := todoList.store.(SequenceStore); ok {
+		store.SetNextNum(todoList.nextNum)
+	}
 
 	// Save to disk.
-	todoList.store.Save(todoList.Todos)
+	var err error
+	if store, ok := todoList.store.(IncrementalStore); ok {
+		err = store.SaveChanges(todoList.changes())
+	} else {
+		err = todoList.store.Save(todoList.all())
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range todoList.Todos {
+		todoList.Todos[i].dirty = false
+		todoList.Todos[i].archived = false
+	}
+	for i := range todoList.Trashed {
+		todoList.Trashed[i].dirty = false
+	}
+	todoList.removed = nil
 	todoList.modified = false
+	todoList.takeSnapshot()
 
+	if record && todoList.journal != nil && len(entry.Changes) > 0 {
+		return todoList.journal.record(entry)
+	}
 	return nil
 }
 
-// setTodos sets the list of TodoItems.
+// changes returns the removed TodoItems followed by the added, updated or trashed ones.
+func (todoList *TodoList) changes() []TodoItem {
+	changes := append([]TodoItem{}, todoList.removed...)
+	for _, todo := range todoList.all() {
+		if todo.dirty {
+			changes = append(changes, todo)
+		}
+	}
+	return changes
+}
+
+// DuplicateIDs returns the IDs shared by several todos, in the order they first appear.
+func DuplicateIDs(todos []TodoItem) []string {
+	count := map[string]int{}
+	ids := []string{}
+	for _, todo := range todos {
+		count[todo.ID]++
+		if count[todo.ID] == 2 {
+			ids = append(ids, todo.ID)
+		}
+	}
+	return ids
+}
+
+// RepairIDs loads the todos from the store, gives a new ID to every todo sharing the ID of an earlier one, and
+// saves them. It returns the todos given a new ID.
+func RepairIDs(store Store) ([]TodoItem, error) {
+	todos, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	repaired := []TodoItem{}
+	seen := map[string]bool{}
+	for i := range todos {
+		todo := &todos[i]
+		for seen[todo.ID] {
+			if err := todo.assignID(); err != nil {
+				return nil, err
+			}
+			todo.dirty = true
+		}
+		seen[todo.ID] = true
+		if todo.dirty {
+			repaired = append(repaired, *todo)
+		}
+	}
+	if len(repaired) == 0 {
+		return repaired, nil
+	}
+
+	// Save everything, since an incremental save can't tell apart the rows sharing an ID.
+	return repaired, store.Save(todos)
+}
+
+// assignNums sets the next number past the last one assigned, and numbers the todos saved before todos were
+// numbered. Their numbers are saved on the next flush.
+func (todoList *TodoList) assignNums() {
+	todoList.nextNum = 1
+	if store, ok := todoList.store.(SequenceStore); ok {
+		todoList.nextNum = max(todoList.nextNum, store.NextNum())
+	}
+	for _, todo := range todoList.all() {
+		todoList.nextNum = max(todoList.nextNum, todo.Num+1)
+	}
+	for _, todos := range [][]TodoItem{todoList.Todos, todoList.Trashed} {
+		for i := range todos {
+			if todos[i].Num == 0 {
+				todos[i].Num = todoList.nextNum
+				todos[i].dirty = true
+				todoList.nextNum++
+				todoList.modified = true
+			}
+		}
+	}
+}
+
+// setTodos sets the list of TodoItems, moving the deleted ones to the trash.
 func (todoList *TodoList) setTodos(todos []TodoItem) {
-	todoList.Todos = todos
+	todoList.Todos = []TodoItem{}
+	todoList.Trashed = []TodoItem{}
+	for _, todo := range todos {
+		if todo.IsDeleted() {
+			todoList.Trashed = append(todoList.Trashed, todo)
+		} else {
+			todoList.Todos = append(todoList.Todos, todo)
+		}
+	}
 }
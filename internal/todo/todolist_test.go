@@ -1,9 +1,14 @@
 package todo
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewTodoList(t *testing.T) {
@@ -73,7 +78,10 @@ func TestTodoListGet(t *testing.T) {
 	}
 
 	id := 2
-	todo := todoList.Get(strconv.Itoa(id))
+	todo, err := todoList.Get(strconv.Itoa(id))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err.Error())
+	}
 	want := makeTodo(id)
 	if todo.ID != want.ID {
 		t.Errorf("Expected %s, got %s", want.ID, todo.ID)
@@ -94,7 +102,7 @@ func TestTodoListUpdate(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected nil, got %s", err.Error())
 	}
-	todo := NewTodoItem("Task 1", "Created for test")
+	todo := newTodoItem(t, "Task 1", "Created for test")
 	todoList.Add(*todo)
 	todo.Title = "Task 2"
 	// Ensure todo is copied by value, not reference.
@@ -114,15 +122,78 @@ func TestTodoListUpdate(t *testing.T) {
 }
 
 func TestTodoDone(t *testing.T) {
-	todo := NewTodoItem("Task 1", "Created for test")
+	todo := newTodoItem(t, "Task 1", "Created for test")
 	todo.Done()
 	if todo.IsDone != true {
 		t.Errorf("Expected true, got %t", todo.IsDone)
 	}
+	completedAt := todo.CompletedAt
+	if completedAt.IsZero() {
+		t.Errorf("Expected completion time, got zero")
+	}
+	// Completing a done todo keeps when it was completed.
+	todo.Done()
+	if !todo.CompletedAt.Equal(completedAt) {
+		t.Errorf("Expected %s, got %s", completedAt, todo.CompletedAt)
+	}
 	todo.Undone()
 	if todo.IsDone != false {
 		t.Errorf("Expected false, got %t", todo.IsDone)
 	}
+	if !todo.CompletedAt.IsZero() {
+		t.Errorf("Expected zero time, got %s", todo.CompletedAt)
+	}
+}
+
+func TestTodoListUpdatedAt(t *testing.T) {
+	for _, name := range []string{"todo.csv", "todo.json", "todo.db"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			todoList, err := NewTodoList(NewStore(path))
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			past := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+			todoList.Add(TodoItem{ID: "a1", Title: "Task 1", CreatedAt: past, UpdatedAt: past})
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+
+			// Updating a todo without changing it keeps UpdatedAt.
+			todo, _ := todoList.Get("a1")
+			todoList.Update(*todo)
+			if todo, _ := todoList.Get("a1"); !todo.UpdatedAt.Equal(past) {
+				t.Errorf("Expected %s, got %s", past, todo.UpdatedAt)
+			}
+
+			todo.Done()
+			todoList.Update(*todo)
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			todoList, err = NewTodoList(NewStore(path))
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			todo, _ = todoList.Get("a1")
+			if !todo.UpdatedAt.After(past) {
+				t.Errorf("Expected after %s, got %s", past, todo.UpdatedAt)
+			}
+			if todo.CompletedAt.IsZero() {
+				t.Errorf("Expected completion time, got zero")
+			}
+
+			// Deleting and restoring a todo update it too.
+			todo.UpdatedAt = past
+			todoList.Todos[0] = *todo
+			if err := todoList.Delete("a1"); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if updatedAt := todoList.Trash()[0].UpdatedAt; !updatedAt.After(past) {
+				t.Errorf("Expected after %s, got %s", past, updatedAt)
+			}
+		})
+	}
 }
 
 func TestTodoDelete(t *testing.T) {
@@ -132,13 +203,15 @@ func TestTodoDelete(t *testing.T) {
 	}
 	origin_len := 5
 	for i := 0; i < origin_len; i++ {
-		todo := NewTodoItem(fmt.Sprintf("Task %d", i), fmt.Sprintf("Desc %d", i))
+		todo := newTodoItem(t, fmt.Sprintf("Task %d", i), fmt.Sprintf("Desc %d", i))
 		todo.ID = strconv.Itoa(i)
 		todoList.Add(*todo)
 	}
 
 	id := 2
-	todoList.Delete(strconv.Itoa(id))
+	if err := todoList.Delete(strconv.Itoa(id)); err != nil {
+		t.Fatalf("Expected nil, got %s", err.Error())
+	}
 	new_len := origin_len - 1
 	if len(todoList.Todos) != new_len {
 		t.Errorf("Expected %d, got %d", new_len, len(todoList.Todos))
@@ -149,3 +222,257 @@ func TestTodoDelete(t *testing.T) {
 		}
 	}
 }
+
+func TestTodoTrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	for i := 1; i <= 3; i++ {
+		todoList.Add(*newTodoItem(t, fmt.Sprintf("Task %d", i), ""))
+	}
+	for _, id := range []string{"1", "2"} {
+		if err := todoList.Delete(id); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+	}
+	// Deleting a todo already in the trash fails.
+	if err := todoList.Delete("1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	// The trash is kept in the store.
+	todoList, err = NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todoList.List()) != 1 || len(todoList.Trash()) != 2 {
+		t.Fatalf("Expected 1 todo and 2 in the trash, got %d and %d", len(todoList.List()), len(todoList.Trash()))
+	}
+
+	// A restored todo goes back to its place in the list.
+	restored, err := todoList.Restore("1")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if restored.IsDeleted() || todoList.List()[0].Title != "Task 1" {
+		t.Errorf("Expected Task 1 first and not deleted, got %v", todoList.List())
+	}
+	if _, err := todoList.Restore("3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+
+	// Only todos deleted before the cutoff are purged.
+	if purged := todoList.Purge(todoList.Trash()[0].DeletedAt.Add(-time.Second)); len(purged) != 0 {
+		t.Errorf("Expected nothing purged, got %v", purged)
+	}
+	purged := todoList.Purge(time.Now())
+	if len(purged) != 1 || purged[0].Title != "Task 2" {
+		t.Errorf("Expected Task 2 purged, got %v", purged)
+	}
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todoList, err = NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todoList.List()) != 2 || len(todoList.Trash()) != 0 {
+		t.Errorf("Expected 2 todos and an empty trash, got %d and %d", len(todoList.List()), len(todoList.Trash()))
+	}
+}
+
+func TestTodoListPrefixes(t *testing.T) {
+	todoList, err := NewTodoList(NewTodoListCsvStore("todos.csv"))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err.Error())
+	}
+	for _, id := range []string{"a1b2c3d4", "a1b2f5e6", "a1f0e1d2", "9f8e"} {
+		todoList.Add(TodoItem{ID: id, Title: "Task " + id})
+	}
+
+	tests := []struct {
+		prefix string
+		want   string
+		err    error
+	}{
+		{"a1b2c", "a1b2c3d4", nil},
+		{"a1f", "a1f0e1d2", nil},
+		{"9f8e", "9f8e", nil},
+		{"a1b2", "", ErrAmbiguousID},
+		{"a", "", ErrAmbiguousID},
+		{"a1b2c3d4e5", "", ErrNotFound},
+		{"9f8e7", "", ErrNotFound},
+		{"ffff", "", ErrNotFound},
+		{"", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			todo, err := todoList.Get(tt.prefix)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if err == nil && todo.ID != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, todo.ID)
+			}
+		})
+	}
+
+	// The error lists the candidates, and nothing is deleted.
+	err = todoList.Delete("a1b2")
+	if err == nil || !strings.Contains(err.Error(), "a1b2c3d4") || !strings.Contains(err.Error(), "a1b2f5e6") {
+		t.Errorf("Expected candidates in error, got %v", err)
+	}
+	if len(todoList.Todos) != 4 {
+		t.Errorf("Expected %d, got %d", 4, len(todoList.Todos))
+	}
+
+	want := map[string]string{
+		"a1b2c3d4": "a1b2c",
+		"a1b2f5e6": "a1b2f",
+		"a1f0e1d2": "a1f0",
+		"9f8e":     "9f8e",
+	}
+	for id, short := range todoList.ShortIDs() {
+		if short != want[id] {
+			t.Errorf("Expected %s, got %s", want[id], short)
+		}
+	}
+}
+
+func TestTodoListNums(t *testing.T) {
+	for _, name := range []string{"todo.csv", "todo.json", "todo.db", "todo.txt"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			todoList, err := NewTodoList(NewStore(path))
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			for i := 1; i <= 3; i++ {
+				todoList.Add(*newTodoItem(t, fmt.Sprintf("Task %d", i), ""))
+			}
+			if err := todoList.Delete("3"); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if err := todoList.Flush(); err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+
+			// The number of the deleted todo is not reused after reloading.
+			todoList, err = NewTodoList(NewStore(path))
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			todoList.Add(*newTodoItem(t, "Task 4", ""))
+			todo, err := todoList.Get("4")
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if todo.Title != "Task 4" {
+				t.Errorf("Expected %s, got %s", "Task 4", todo.Title)
+			}
+			todo, err = todoList.Get("2")
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if todo.Title != "Task 2" {
+				t.Errorf("Expected %s, got %s", "Task 2", todo.Title)
+			}
+			if _, err := todoList.Get("3"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected %v, got %v", ErrNotFound, err)
+			}
+		})
+	}
+
+	// Todos saved before numbering are numbered in order when loaded.
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	data := "a1,Task 1,,false,2026-10-14T09:30:00Z,2026-10-14T09:30:00Z\nb2,Task 2,,false,2026-10-14T09:30:00Z,2026-10-14T09:30:00Z\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	for i, todo := range todoList.List() {
+		if todo.Num != i+1 {
+			t.Errorf("Expected %d, got %d", i+1, todo.Num)
+		}
+	}
+}
+
+func TestTodoListDeletedNum(t *testing.T) {
+	todoList, err := NewTodoList(NewTodoListCsvStore("todos.csv"))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err.Error())
+	}
+	for _, id := range []string{"a1b2c3d4", "b2c3d4e5", "f0e1d2c3", "3c4d5e6f"} {
+		todoList.Add(TodoItem{ID: id, Title: "Task " + id})
+	}
+	if err := todoList.Delete("3"); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	// 3 no longer names a todo, even though an ID starts with it.
+	if _, err := todoList.Get("3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+	if err := todoList.Delete("3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v, got %v", ErrNotFound, err)
+	}
+	todo, err := todoList.Get("3c4d")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if todo.ID != "3c4d5e6f" {
+		t.Errorf("Expected %s, got %s", "3c4d5e6f", todo.ID)
+	}
+	if len(todoList.Todos) != 3 {
+		t.Errorf("Expected %d, got %d", 3, len(todoList.Todos))
+	}
+}
+
+func TestRepairIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	data := "a1,Task 1,,false,2026-10-14T09:30:00Z,2026-10-14T09:30:00Z\n" +
+		"a1,Task 2,,false,2026-10-14T09:30:00Z,2026-10-14T09:30:00Z\n" +
+		"b2,Task 3,,false,2026-10-14T09:30:00Z,2026-10-14T09:30:00Z\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTodoList(NewStore(path)); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("Expected %v, got %v", ErrDuplicateID, err)
+	}
+
+	repaired, err := RepairIDs(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(repaired) != 1 || repaired[0].Title != "Task 2" || repaired[0].ID == "a1" {
+		t.Errorf("Expected Task 2 with a new ID, got %v", repaired)
+	}
+
+	todoList, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	todo, err := todoList.Get("a1")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if todo.Title != "Task 1" {
+		t.Errorf("Expected %s, got %s", "Task 1", todo.Title)
+	}
+	if len(todoList.List()) != 3 {
+		t.Errorf("Expected %d, got %d", 3, len(todoList.List()))
+	}
+
+	if repaired, err := RepairIDs(NewStore(path)); err != nil || len(repaired) != 0 {
+		t.Errorf("Expected nothing to repair, got %v, %v", repaired, err)
+	}
+}
@@ -1,21 +1,19 @@
 package todo
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
-	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// Store is an interface that defines the methods to save and load todos from disk.
-type Store interface {
-	Save(todos []TodoItem) error
-	Load() ([]TodoItem, error)
-}
-
 // TodoListCsvStore is a store that saves and loads todos to and from a CSV file.
 type TodoListCsvStore struct {
 	filepath string
+	version  fileVersion
+	nextNum  int
 }
 
 // NewTodoListCsvStore creates a new instance of TodoListCsvStore.
@@ -25,15 +23,15 @@ func NewTodoListCsvStore(filepath string) *TodoListCsvStore {
 	}
 }
 
+// csvNextNumRecord is the first field of the record holding the next todo number. IDs never start with `#`.
+const csvNextNumRecord = "#next_num"
+
 // Save writes the list of todos to a CSV file.
+// The file is replaced atomically and the previous version is kept as a backup. ErrConflict is returned
+// if the file was changed by another process since it was loaded.
 func (t *TodoListCsvStore) Save(todos []TodoItem) error {
-	file, err := os.Create(t.filepath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
 	records := make([][]string, len(todos))
 	for i := range todos {
 		todo := &todos[i]
@@ -43,9 +41,22 @@ func (t *TodoListCsvStore) Save(todos []TodoItem) error {
 		records[i] = append(records[i], strconv.FormatBool(todo.IsDone))
 		records[i] = append(records[i], todo.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
 		records[i] = append(records[i], todo.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+		records[i] = append(records[i], string(todo.Priority))
+		records[i] = append(records[i], formatOptionalTime(todo.DueAt))
+		records[i] = append(records[i], strings.Join(todo.Tags, " "))
+		records[i] = append(records[i], todo.Project)
+		records[i] = append(records[i], strconv.Itoa(todo.Num))
+		records[i] = append(records[i], formatOptionalTime(todo.DeletedAt))
+		records[i] = append(records[i], formatOptionalTime(todo.CompletedAt))
+		records[i] = append(records[i], string(todo.Recurrence))
+		records[i] = append(records[i], todo.ParentID)
+		records[i] = append(records[i], strings.Join(todo.Dependencies, " "))
+	}
+	if t.nextNum > 0 {
+		records = append([][]string{{csvNextNumRecord, strconv.Itoa(t.nextNum)}}, records...)
 	}
 
-	if err = writer.WriteAll(records); err != nil {
+	if err := writer.WriteAll(records); err != nil {
 		return fmt.Errorf("%w: Error writing data to CSV", err)
 	}
 
@@ -54,25 +65,42 @@ func (t *TodoListCsvStore) Save(todos []TodoItem) error {
 		return fmt.Errorf("%w: Error flushing data to CSV", err)
 	}
 
-	return nil
+	return saveFile(t.filepath, buf.Bytes(), &t.version)
 }
 
 // Load reads the CSV file and returns the list of todos.
+// If the file cannot be parsed, the backup of the previous version is loaded instead, and Recovered reports it.
 func (t *TodoListCsvStore) Load() ([]TodoItem, error) {
-	file, err := os.OpenFile(t.filepath, os.O_RDONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+	return loadFile(t.filepath, &t.version, t.parse)
+}
+
+// Recovered returns an error wrapping ErrRecovered if the last Load loaded the backup of the file.
+func (t *TodoListCsvStore) Recovered() error {
+	return t.version.recovered
+}
 
-	reader := csv.NewReader(file)
+// parse decodes the contents of a CSV file.
+// Columns added after the first six are optional, so files written by older versions still load.
+// A record starting with `#next_num` holds the next todo number instead of a todo.
+func (t *TodoListCsvStore) parse(data []byte) ([]TodoItem, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
 	}
 
-	todos := make([]TodoItem, len(records))
+	todos := make([]TodoItem, 0, len(records))
 	for i, rec := range records {
+		if len(rec) == 2 && rec[0] == csvNextNumRecord {
+			if t.nextNum, err = strconv.Atoi(rec[1]); err != nil {
+				return nil, fmt.Errorf("%s. Expected next todo number on line %d, got %s", err.Error(), i+1, rec[1])
+			}
+			continue
+		}
+		if len(rec) < 6 {
+			return nil, fmt.Errorf("Expected at least 6 columns on line %d, got %d", i+1, len(rec))
+		}
 		todo, err := NewTodoItemFromStrings(
 			rec[0],
 			rec[1],
@@ -84,8 +112,80 @@ func (t *TodoListCsvStore) Load() ([]TodoItem, error) {
 		if err != nil {
 			return nil, err
 		}
-		todos[i] = *todo
+		if len(rec) > 6 {
+			if todo.Priority, err = ParsePriority(rec[6]); err != nil {
+				return nil, err
+			}
+		}
+		if len(rec) > 7 {
+			if todo.DueAt, err = parseOptionalTime(rec[7], "DueAt"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rec) > 8 {
+			todo.Tags = strings.Fields(rec[8])
+		}
+		if len(rec) > 9 {
+			todo.Project = rec[9]
+		}
+		if len(rec) > 10 {
+			if todo.Num, err = strconv.Atoi(rec[10]); err != nil {
+				return nil, fmt.Errorf("%s. Expected `Num` as number, got %s", err.Error(), rec[10])
+			}
+		}
+		if len(rec) > 11 {
+			if todo.DeletedAt, err = parseOptionalTime(rec[11], "DeletedAt"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rec) > 12 {
+			if todo.CompletedAt, err = parseOptionalTime(rec[12], "CompletedAt"); err != nil {
+				return nil, err
+			}
+		}
+		if len(rec) > 13 {
+			if todo.Recurrence, err = ParseRecurrence(rec[13]); err != nil {
+				return nil, err
+			}
+		}
+		if len(rec) > 14 {
+			todo.ParentID = rec[14]
+		}
+		if len(rec) > 15 {
+			todo.Dependencies = strings.Fields(rec[15])
+		}
+		todos = append(todos, *todo)
 	}
 
 	return todos, nil
 }
+
+// NextNum returns the next todo number read by Load, or 0 if the file has none.
+func (t *TodoListCsvStore) NextNum() int {
+	return t.nextNum
+}
+
+// SetNextNum sets the next todo number written by Save.
+func (t *TodoListCsvStore) SetNextNum(n int) {
+	t.nextNum = n
+}
+
+// formatOptionalTime formats a timestamp that may be unset, in which case it is empty.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
+
+// parseOptionalTime parses a timestamp written by formatOptionalTime.
+func parseOptionalTime(s, field string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02T15:04:05Z07:00", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s. Expected `%s` as timestamp, got %s", err.Error(), field, s)
+	}
+	return t, nil
+}
@@ -0,0 +1,143 @@
+package todo
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTodoListCsvStore(t *testing.T) {
+	t.Run("Save keeps a backup", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.csv")
+		store := NewTodoListCsvStore(path)
+		first := newTodoItem(t, "Task 1", "Created for test")
+		if err := store.Save([]TodoItem{*first}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		second := newTodoItem(t, "Task 2", "Created for test")
+		if err := store.Save([]TodoItem{*first, *second}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+
+		backup, err := NewTodoListCsvStore(backupPath(path)).Load()
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if len(backup) != 1 {
+			t.Errorf("Expected %d, got %d", 1, len(backup))
+		}
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		if len(entries) != 2 {
+			t.Errorf("Expected %d, got %d", 2, len(entries))
+		}
+	})
+
+	t.Run("Load falls back to backup", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.csv")
+		store := NewTodoListCsvStore(path)
+		todo := newTodoItem(t, "Task 1", "Created for test")
+		if err := store.Save([]TodoItem{*todo}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if err := store.Save([]TodoItem{*todo}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		// Simulate a torn write of the main file.
+		if err := os.WriteFile(path, []byte(todo.ID+",\"Task"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		todos, err := store.Load()
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if len(todos) != 1 || todos[0].ID != todo.ID {
+			t.Errorf("Expected %s, got %v", todo.ID, todos)
+		}
+		if err := store.Recovered(); !errors.Is(err, ErrRecovered) {
+			t.Errorf("Expected %v, got %v", ErrRecovered, err)
+		}
+
+		// The torn file is kept aside rather than overwritten by the next save.
+		if data, err := os.ReadFile(corruptPath(path)); err != nil || string(data) != todo.ID+",\"Task" {
+			t.Errorf("Expected the torn file at %s, got %q, %v", corruptPath(path), data, err)
+		}
+
+		// Saving doesn't replace the good backup with the torn file.
+		if err := store.Save(todos); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		backup, err := NewTodoListCsvStore(backupPath(path)).Load()
+		if err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if len(backup) != 1 || backup[0].ID != todo.ID {
+			t.Errorf("Expected %s, got %v", todo.ID, backup)
+		}
+	})
+
+	t.Run("Save keeps permissions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.csv")
+		store := NewTodoListCsvStore(path)
+		if err := store.Save([]TodoItem{*newTodoItem(t, "Task 1", "Created for test")}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Save([]TodoItem{*newTodoItem(t, "Task 2", "Created for test")}); err != nil {
+			t.Fatalf("Expected nil, got %s", err)
+		}
+		for _, p := range []string{path, backupPath(path)} {
+			info, err := os.Stat(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("Expected %v, got %v for %s", fs.FileMode(0600), info.Mode().Perm(), p)
+			}
+		}
+	})
+}
+
+func TestTodoListCsvStoreConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	first, err := NewTodoList(NewTodoListCsvStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	second, err := NewTodoList(NewTodoListCsvStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	first.Add(*newTodoItem(t, "Task 1", "Created for test"))
+	if err := first.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	second.Add(*newTodoItem(t, "Task 2", "Created for test"))
+	if err := second.Flush(); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected %s, got %v", ErrConflict, err)
+	}
+}
+
+func TestTodoListCsvStoreOldColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.csv")
+	data := "1,Task 1,Created for test,false,2021-09-01T00:00:00Z,2021-09-01T00:00:00Z\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	todos, err := NewTodoListCsvStore(path).Load()
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todos) != 1 {
+		t.Fatalf("Expected %d, got %d", 1, len(todos))
+	}
+	if todos[0].Priority != PriorityNone {
+		t.Errorf("Expected %s, got %s", PriorityNone, todos[0].Priority)
+	}
+}
@@ -0,0 +1,98 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// todoListJsonVersion is the schema version written to and accepted from JSON files.
+const todoListJsonVersion = 1
+
+// todoListJsonDocument is the layout of the JSON file.
+type todoListJsonDocument struct {
+	Version int        `json:"version"`
+	NextNum int        `json:"next_num,omitempty"`
+	Todos   []TodoItem `json:"todos"`
+}
+
+// TodoListJsonStore is a store that saves and loads todos to and from a JSON file.
+type TodoListJsonStore struct {
+	filepath string
+	version  fileVersion
+	nextNum  int
+}
+
+// NewTodoListJsonStore creates a new instance of TodoListJsonStore.
+func NewTodoListJsonStore(filepath string) *TodoListJsonStore {
+	return &TodoListJsonStore{
+		filepath: filepath,
+	}
+}
+
+// Save writes the list of todos to a JSON file.
+// The file is replaced atomically and the previous version is kept as a backup. ErrConflict is returned
+// if the file was changed by another process since it was loaded.
+func (t *TodoListJsonStore) Save(todos []TodoItem) error {
+	if todos == nil {
+		// Write an empty array rather than null so the file stays easy to script against.
+		todos = []TodoItem{}
+	}
+	doc := todoListJsonDocument{
+		Version: todoListJsonVersion,
+		NextNum: t.nextNum,
+		Todos:   todos,
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("%w: Error writing data to JSON", err)
+	}
+
+	return saveFile(t.filepath, buf.Bytes(), &t.version)
+}
+
+// Load reads the JSON file and returns the list of todos.
+// If the file cannot be parsed, the backup of the previous version is loaded instead, and Recovered reports it.
+func (t *TodoListJsonStore) Load() ([]TodoItem, error) {
+	return loadFile(t.filepath, &t.version, t.parse)
+}
+
+// Recovered returns an error wrapping ErrRecovered if the last Load loaded the backup of the file.
+func (t *TodoListJsonStore) Recovered() error {
+	return t.version.recovered
+}
+
+// parse decodes the contents of a JSON file.
+func (t *TodoListJsonStore) parse(data []byte) ([]TodoItem, error) {
+	// A new or empty file is an empty list.
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []TodoItem{}, nil
+	}
+
+	var doc todoListJsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: Error reading data from JSON", err)
+	}
+	if doc.Version < 1 || doc.Version > todoListJsonVersion {
+		return nil, fmt.Errorf("Unsupported JSON schema version %d, expected %d", doc.Version, todoListJsonVersion)
+	}
+	if doc.Todos == nil {
+		doc.Todos = []TodoItem{}
+	}
+	t.nextNum = doc.NextNum
+
+	return doc.Todos, nil
+}
+
+// NextNum returns the next todo number read by Load, or 0 if the file has none.
+func (t *TodoListJsonStore) NextNum() int {
+	return t.nextNum
+}
+
+// SetNextNum sets the next todo number written by Save.
+func (t *TodoListJsonStore) SetNextNum(n int) {
+	t.nextNum = n
+}
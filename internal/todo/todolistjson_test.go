@@ -0,0 +1,59 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTodoListJsonStore(t *testing.T) {
+	t.Run("Load empty file", func(t *testing.T) {
+		store := NewTodoListJsonStore(filepath.Join(t.TempDir(), "todo.json"))
+		todos, err := store.Load()
+		if err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+		if len(todos) != 0 {
+			t.Errorf("Expected %d, got %d", 0, len(todos))
+		}
+	})
+
+	t.Run("Save and load", func(t *testing.T) {
+		store := NewTodoListJsonStore(filepath.Join(t.TempDir(), "todo.json"))
+		todo := newTodoItem(t, "Task 1", "Created for test")
+		todo.Done()
+		if err := store.Save([]TodoItem{*todo}); err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+
+		todos, err := store.Load()
+		if err != nil {
+			t.Errorf("Expected nil, got %s", err)
+		}
+		if len(todos) != 1 {
+			t.Fatalf("Expected %d, got %d", 1, len(todos))
+		}
+		if todos[0].ID != todo.ID {
+			t.Errorf("Expected %s, got %s", todo.ID, todos[0].ID)
+		}
+		if todos[0].Title != todo.Title {
+			t.Errorf("Expected %s, got %s", todo.Title, todos[0].Title)
+		}
+		if todos[0].IsDone != true {
+			t.Errorf("Expected true, got %t", todos[0].IsDone)
+		}
+		if !todos[0].CreatedAt.Equal(todo.CreatedAt) {
+			t.Errorf("Expected %s, got %s", todo.CreatedAt, todos[0].CreatedAt)
+		}
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todo.json")
+		if err := os.WriteFile(path, []byte(`{"version": 99, "todos": []}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewTodoListJsonStore(path).Load(); err == nil {
+			t.Errorf("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,346 @@
+package todo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations are applied in order. The database's user_version records how many have been applied.
+var sqliteMigrations = []string{
+	`CREATE TABLE todos (
+		id          TEXT PRIMARY KEY,
+		title       TEXT NOT NULL,
+		description TEXT NOT NULL,
+		is_done     INTEGER NOT NULL,
+		created_at  TEXT NOT NULL,
+		updated_at  TEXT NOT NULL
+	)`,
+	`CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+	`ALTER TABLE todos ADD COLUMN priority TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN due_at TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN tags TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN project TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN num INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE todos ADD COLUMN deleted_at TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN completed_at TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN recurrence TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN parent_id TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE todos ADD COLUMN depends_on TEXT NOT NULL DEFAULT ''`,
+}
+
+// TodoListSqliteStore is a store that saves and loads todos to and from a SQLite database.
+// It implements IncrementalStore, so flushing a TodoList only writes the changed rows.
+type TodoListSqliteStore struct {
+	filepath string
+	// revision is the database revision seen by Load, used to detect writes by other processes.
+	revision int64
+	loaded   bool
+	nextNum  int
+}
+
+// NewTodoListSqliteStore creates a new instance of TodoListSqliteStore.
+func NewTodoListSqliteStore(filepath string) *TodoListSqliteStore {
+	return &TodoListSqliteStore{
+		filepath: filepath,
+	}
+}
+
+// Save replaces all rows in the database with the list of todos.
+// ErrConflict is returned if the database was changed by another process since it was loaded.
+func (t *TodoListSqliteStore) Save(todos []TodoItem) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return t.transaction(db, func(tx *sql.Tx) error {
+		if err := t.bumpRevision(tx); err != nil {
+			return err
+		}
+		if err := t.writeNextNum(tx); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM todos"); err != nil {
+			return err
+		}
+		for i := range todos {
+			if err := t.upsert(tx, &todos[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveChanges deletes the todos marked as deleted and inserts or updates the rest.
+// ErrConflict is returned if the database was changed by another process since it was loaded.
+func (t *TodoListSqliteStore) SaveChanges(todos []TodoItem) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return t.transaction(db, func(tx *sql.Tx) error {
+		if err := t.bumpRevision(tx); err != nil {
+			return err
+		}
+		if err := t.writeNextNum(tx); err != nil {
+			return err
+		}
+		for i := range todos {
+			todo := &todos[i]
+			if todo.deleted {
+				if _, err := tx.Exec("DELETE FROM todos WHERE id = ?", todo.ID); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := t.upsert(tx, todo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load reads the database and returns the list of todos in the order they were added.
+func (t *TodoListSqliteStore) Load() ([]TodoItem, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if t.revision, err = t.readRevision(db); err != nil {
+		return nil, err
+	}
+	t.loaded = true
+	err = db.QueryRow("SELECT CAST(value AS INTEGER) FROM meta WHERE key = 'next_num'").Scan(&t.nextNum)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT id, title, description, is_done, created_at, updated_at, priority, due_at, tags, project, num, deleted_at, completed_at, recurrence, parent_id, depends_on FROM todos ORDER BY num, rowid")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := []TodoItem{}
+	for rows.Next() {
+		var todo TodoItem
+		var createdAt, updatedAt, dueAt, tags, deletedAt, completedAt, dependsOn string
+		if err := rows.Scan(&todo.ID, &todo.Title, &todo.Description, &todo.IsDone, &createdAt, &updatedAt, &todo.Priority, &dueAt, &tags, &todo.Project, &todo.Num, &deletedAt, &completedAt, &todo.Recurrence, &todo.ParentID, &dependsOn); err != nil {
+			return nil, err
+		}
+		if todo.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("%s. Expected `CreatedAt` as timestamp, got %s", err.Error(), createdAt)
+		}
+		if todo.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			return nil, fmt.Errorf("%s. Expected `UpdatedAt` as timestamp, got %s", err.Error(), updatedAt)
+		}
+		if todo.DueAt, err = parseSqliteOptionalTime(dueAt, "DueAt"); err != nil {
+			return nil, err
+		}
+		if todo.DeletedAt, err = parseSqliteOptionalTime(deletedAt, "DeletedAt"); err != nil {
+			return nil, err
+		}
+		if todo.CompletedAt, err = parseSqliteOptionalTime(completedAt, "CompletedAt"); err != nil {
+			return nil, err
+		}
+		todo.Tags = strings.Fields(tags)
+		todo.Dependencies = strings.Fields(dependsOn)
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// open opens the database and brings its schema up to date.
+func (t *TodoListSqliteStore) open() (*sql.DB, error) {
+	db, err := sql.Open("sqlite", t.filepath)
+	if err != nil {
+		return nil, err
+	}
+	// A single connection keeps pragmas and transactions on the same database handle.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := t.migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: Error migrating SQLite database", err)
+	}
+
+	return db, nil
+}
+
+// migrate applies the migrations that have not been applied to the database yet.
+func (t *TodoListSqliteStore) migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("Unsupported SQLite schema version %d, expected at most %d", version, len(sqliteMigrations))
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		err := t.transaction(db, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+				return err
+			}
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transaction runs fn inside a transaction, committing on success and rolling back on error.
+func (t *TodoListSqliteStore) transaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// readRevision returns the current revision of the database. It is 0 until the first save.
+func (t *TodoListSqliteStore) readRevision(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}) (int64, error) {
+	var revision int64
+	err := q.QueryRow("SELECT CAST(value AS INTEGER) FROM meta WHERE key = 'revision'").Scan(&revision)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return revision, err
+}
+
+// bumpRevision checks that the database is still at the revision seen by Load and advances it.
+func (t *TodoListSqliteStore) bumpRevision(tx *sql.Tx) error {
+	revision, err := t.readRevision(tx)
+	if err != nil {
+		return err
+	}
+	if t.loaded && revision != t.revision {
+		return fmt.Errorf("%w: %s changed since it was loaded, run the command again", ErrConflict, t.filepath)
+	}
+	revision++
+	_, err = tx.Exec(
+		"INSERT INTO meta (key, value) VALUES ('revision', ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		revision,
+	)
+	if err != nil {
+		return err
+	}
+	t.revision = revision
+	t.loaded = true
+	return nil
+}
+
+// writeNextNum saves the next todo number, if it was set.
+func (t *TodoListSqliteStore) writeNextNum(tx *sql.Tx) error {
+	if t.nextNum == 0 {
+		return nil
+	}
+	_, err := tx.Exec(
+		"INSERT INTO meta (key, value) VALUES ('next_num', ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		t.nextNum,
+	)
+	return err
+}
+
+// NextNum returns the next todo number read by Load, or 0 if the database has none.
+func (t *TodoListSqliteStore) NextNum() int {
+	return t.nextNum
+}
+
+// SetNextNum sets the next todo number written by the next save.
+func (t *TodoListSqliteStore) SetNextNum(n int) {
+	t.nextNum = n
+}
+
+// upsert inserts the todo, or updates the existing row with the same ID.
+func (t *TodoListSqliteStore) upsert(tx *sql.Tx, todo *TodoItem) error {
+	_, err := tx.Exec(
+		`INSERT INTO todos (id, title, description, is_done, created_at, updated_at, priority, due_at, tags, project, num, deleted_at, completed_at, recurrence, parent_id, depends_on)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			is_done = excluded.is_done,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at,
+			priority = excluded.priority,
+			due_at = excluded.due_at,
+			tags = excluded.tags,
+			project = excluded.project,
+			num = excluded.num,
+			deleted_at = excluded.deleted_at,
+			completed_at = excluded.completed_at,
+			recurrence = excluded.recurrence,
+			parent_id = excluded.parent_id,
+			depends_on = excluded.depends_on`,
+		todo.ID,
+		todo.Title,
+		todo.Description,
+		todo.IsDone,
+		todo.CreatedAt.Format(time.RFC3339Nano),
+		todo.UpdatedAt.Format(time.RFC3339Nano),
+		string(todo.Priority),
+		formatSqliteOptionalTime(todo.DueAt),
+		strings.Join(todo.Tags, " "),
+		todo.Project,
+		todo.Num,
+		formatSqliteOptionalTime(todo.DeletedAt),
+		formatSqliteOptionalTime(todo.CompletedAt),
+		string(todo.Recurrence),
+		todo.ParentID,
+		strings.Join(todo.Dependencies, " "),
+	)
+	return err
+}
+
+// formatSqliteOptionalTime formats a timestamp that may be unset, in which case it is empty.
+func formatSqliteOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseSqliteOptionalTime parses a timestamp written by formatSqliteOptionalTime.
+func parseSqliteOptionalTime(s, field string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s. Expected `%s` as timestamp, got %s", err.Error(), field, s)
+	}
+	return t, nil
+}
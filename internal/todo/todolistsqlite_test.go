@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTodoListSqliteStore(t *testing.T) {
+	store := NewTodoListSqliteStore(filepath.Join(t.TempDir(), "todo.db"))
+	todoList, err := NewTodoList(store)
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	first := newTodoItem(t, "Task 1", "Created for test")
+	first.ID = "1"
+	second := newTodoItem(t, "Task 2", "Created for test")
+	second.ID = "2"
+	third := newTodoItem(t, "Task 3", "Created for test")
+	third.ID = "3"
+	todoList.Add(*first)
+	todoList.Add(*second)
+	todoList.Add(*third)
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	// Only the purged and updated rows are written on the next flush.
+	todoList.Delete("2")
+	todoList.Purge(time.Now())
+	third.Done()
+	todoList.Update(*third)
+	changes := todoList.changes()
+	if len(changes) != 2 {
+		t.Errorf("Expected %d, got %d", 2, len(changes))
+	}
+	if err := todoList.Flush(); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+
+	todos, err := store.Load()
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(todos) != 2 {
+		t.Fatalf("Expected %d, got %d", 2, len(todos))
+	}
+	if todos[0].ID != "1" {
+		t.Errorf("Expected %s, got %s", "1", todos[0].ID)
+	}
+	if todos[1].ID != "3" {
+		t.Errorf("Expected %s, got %s", "3", todos[1].ID)
+	}
+	if todos[1].IsDone != true {
+		t.Errorf("Expected true, got %t", todos[1].IsDone)
+	}
+	if !todos[0].CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Expected %s, got %s", first.CreatedAt, todos[0].CreatedAt)
+	}
+}
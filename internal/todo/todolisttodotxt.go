@@ -0,0 +1,331 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TodoListTodoTxtStore is a store that saves and loads todos to and from a file in the todo.txt format, one
+// todo per line:
+//
+//	(A) 2026-10-01 Call mom +family @phone due:2026-10-20 id:3fa1c2d4e5b6a7f8
+//	x 2026-10-18 2026-10-01 Water plants +home pri:B id:9c0d1e2f3a4b5c6d
+//
+// Lines start with `x` if the todo is done and the priority of incomplete todos, followed by the completion
+// and creation dates. The project and tags are `+project` and `@tag` tokens, and the other fields are
+// `key:value` extensions: due, rec, desc, parent, dep, pri for done todos, num, id, and created, updated,
+// completed and deleted holding timestamps more precise than the dates. Other tokens are kept in the title,
+// so extensions written by other todo.txt apps survive a round trip. Words of the title that start with one of
+// these keys, such as `rec:room`, are written with the colon encoded as %3A. A first line such as
+// `#next_num:4` holds the next todo number.
+type TodoListTodoTxtStore struct {
+	filepath string
+	version  fileVersion
+	nextNum  int
+}
+
+// NewTodoListTodoTxtStore creates a new instance of TodoListTodoTxtStore.
+func NewTodoListTodoTxtStore(filepath string) *TodoListTodoTxtStore {
+	return &TodoListTodoTxtStore{
+		filepath: filepath,
+	}
+}
+
+// todoTxtNextNumLine is the prefix of the line holding the next todo number.
+const todoTxtNextNumLine = "#next_num:"
+
+// todoTxtDate is the layout of the dates of todo.txt.
+const todoTxtDate = "2006-01-02"
+
+// todoTxtKeys are the keys of the extensions read by ParseTodoTxt.
+var todoTxtKeys = map[string]bool{
+	"due": true, "rec": true, "desc": true, "parent": true, "dep": true, "pri": true, "num": true, "id": true,
+	"created": true, "updated": true, "completed": true, "deleted": true,
+}
+
+// Save writes the list of todos to a todo.txt file.
+// The file is replaced atomically and the previous version is kept as a backup. ErrConflict is returned
+// if the file was changed by another process since it was loaded.
+func (t *TodoListTodoTxtStore) Save(todos []TodoItem) error {
+	var buf bytes.Buffer
+	if t.nextNum > 0 {
+		buf.WriteString(todoTxtNextNumLine + strconv.Itoa(t.nextNum) + "\n")
+	}
+	for i := range todos {
+		buf.WriteString(FormatTodoTxt(todos[i]))
+		buf.WriteByte('\n')
+	}
+	return saveFile(t.filepath, buf.Bytes(), &t.version)
+}
+
+// Load reads the todo.txt file and returns the list of todos.
+// If the file cannot be parsed, the backup of the previous version is loaded instead, and Recovered reports it.
+func (t *TodoListTodoTxtStore) Load() ([]TodoItem, error) {
+	return loadFile(t.filepath, &t.version, t.parse)
+}
+
+// Recovered returns an error wrapping ErrRecovered if the last Load loaded the backup of the file.
+func (t *TodoListTodoTxtStore) Recovered() error {
+	return t.version.recovered
+}
+
+// NextNum returns the next todo number read by Load, or 0 if the file has none.
+func (t *TodoListTodoTxtStore) NextNum() int {
+	return t.nextNum
+}
+
+// SetNextNum sets the next todo number written by Save.
+func (t *TodoListTodoTxtStore) SetNextNum(n int) {
+	t.nextNum = n
+}
+
+// parse decodes the contents of a todo.txt file. Blank lines are skipped, and a line starting with
+// `#next_num:` holds the next todo number instead of a todo.
+func (t *TodoListTodoTxtStore) parse(data []byte) ([]TodoItem, error) {
+	todos := []TodoItem{}
+	ids := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, todoTxtNextNumLine); ok {
+			var err error
+			if t.nextNum, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%s. Expected next todo number on line %d, got %s", err.Error(), i, value)
+			}
+			continue
+		}
+		todo, err := ParseTodoTxt(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d", err, i)
+		}
+		// Lines added by other apps have no ID. Derive one from the line, so it stays the same until the
+		// line is saved with it, and tell identical lines apart by their occurrence.
+		for n := 0; todo.ID == ""; n++ {
+			if id := DerivedID(fmt.Sprintf("%s\x00%d", line, n)); !ids[id] {
+				todo.ID = id
+			}
+		}
+		ids[todo.ID] = true
+		todos = append(todos, *todo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: Error reading data from todo.txt", err)
+	}
+	return todos, nil
+}
+
+// FormatTodoTxt formats the todo as a line of todo.txt, without the line break.
+func FormatTodoTxt(todo TodoItem) string {
+	parts := []string{}
+	if todo.IsDone {
+		parts = append(parts, "x")
+	} else if todo.Priority != PriorityNone {
+		parts = append(parts, "("+string(todo.Priority)+")")
+	}
+	// A completion date must be followed by a creation date, and a done todo's single date would be read as
+	// its completion date, so a done todo without a completion time has neither.
+	if !todo.IsDone || !todo.CompletedAt.IsZero() {
+		if todo.IsDone {
+			parts = append(parts, todo.CompletedAt.Local().Format(todoTxtDate))
+		}
+		if !todo.CreatedAt.IsZero() {
+			parts = append(parts, todo.CreatedAt.Local().Format(todoTxtDate))
+		}
+	}
+	for _, word := range strings.Fields(todo.Title) {
+		parts = append(parts, escapeTitleWord(word))
+	}
+	if todo.Project != "" {
+		parts = append(parts, "+"+todo.Project)
+	}
+	for _, tag := range todo.Tags {
+		parts = append(parts, "@"+tag)
+	}
+
+	extension := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+":"+value)
+		}
+	}
+	if todo.HasDue() {
+		if todo.DueDateOnly() {
+			extension("due", todo.DueAt.Local().Format(todoTxtDate))
+		} else {
+			extension("due", formatOptionalTime(todo.DueAt))
+		}
+	}
+	extension("rec", string(todo.Recurrence))
+	extension("desc", url.PathEscape(todo.Description))
+	extension("parent", todo.ParentID)
+	extension("dep", strings.Join(todo.Dependencies, ","))
+	if todo.IsDone {
+		extension("pri", string(todo.Priority))
+	}
+	if todo.Num > 0 {
+		extension("num", strconv.Itoa(todo.Num))
+	}
+	extension("id", todo.ID)
+	extension("created", formatOptionalTime(todo.CreatedAt))
+	extension("updated", formatOptionalTime(todo.UpdatedAt))
+	extension("completed", formatOptionalTime(todo.CompletedAt))
+	extension("deleted", formatOptionalTime(todo.DeletedAt))
+	return strings.Join(parts, " ")
+}
+
+// ParseTodoTxt parses a line of todo.txt written by FormatTodoTxt or another todo.txt app. The created and
+// completed timestamps are used when they fall on the dates at the start of the line, so a date changed by
+// another app wins. A todo without an ID extension has an empty ID.
+func ParseTodoTxt(line string) (*TodoItem, error) {
+	todo := &TodoItem{}
+	tokens := strings.Fields(line)
+	next := func() (string, bool) {
+		if len(tokens) == 0 {
+			return "", false
+		}
+		return tokens[0], true
+	}
+
+	if token, ok := next(); ok && token == "x" {
+		todo.IsDone = true
+		tokens = tokens[1:]
+	}
+	if token, ok := next(); ok && len(token) == 3 && token[0] == '(' && token[2] == ')' && token[1] >= 'A' && token[1] <= 'Z' {
+		todo.Priority = Priority(token[1:2])
+		tokens = tokens[1:]
+	}
+	dates := []time.Time{}
+	for len(dates) < 2 {
+		token, ok := next()
+		if !ok {
+			break
+		}
+		date, err := time.ParseInLocation(todoTxtDate, token, time.Local)
+		if err != nil {
+			break
+		}
+		dates = append(dates, date)
+		tokens = tokens[1:]
+	}
+	var completedOn, createdOn time.Time
+	switch {
+	case todo.IsDone && len(dates) == 2:
+		completedOn, createdOn = dates[0], dates[1]
+	case todo.IsDone && len(dates) == 1:
+		completedOn = dates[0]
+	case len(dates) > 0:
+		createdOn = dates[0]
+		// A second date is part of the title.
+		if len(dates) == 2 {
+			tokens = append([]string{dates[1].Format(todoTxtDate)}, tokens...)
+		}
+	}
+
+	var created, completed time.Time
+	title := []string{}
+	for _, token := range tokens {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" {
+			title = append(title, unescapeTitleWord(token))
+			continue
+		}
+		var err error
+		switch key {
+		case "due":
+			todo.DueAt, err = time.ParseInLocation(todoTxtDate, value, time.Local)
+			if err != nil {
+				todo.DueAt, err = parseOptionalTime(value, "due")
+			}
+		case "rec":
+			todo.Recurrence, err = ParseRecurrence(value)
+		case "desc":
+			todo.Description, err = url.PathUnescape(value)
+		case "parent":
+			todo.ParentID = value
+		case "dep":
+			todo.Dependencies = strings.FieldsFunc(value, func(r rune) bool { return r == ',' })
+		case "pri":
+			todo.Priority, err = ParsePriority(value)
+		case "num":
+			if todo.Num, err = strconv.Atoi(value); err != nil {
+				err = fmt.Errorf("%s. Expected `num` as number, got %s", err.Error(), value)
+			}
+		case "id":
+			todo.ID = value
+		case "created":
+			created, err = parseOptionalTime(value, "created")
+		case "updated":
+			todo.UpdatedAt, err = parseOptionalTime(value, "updated")
+		case "completed":
+			completed, err = parseOptionalTime(value, "completed")
+		case "deleted":
+			todo.DeletedAt, err = parseOptionalTime(value, "deleted")
+		default:
+			title = append(title, unescapeTitleWord(token))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	parsed := ParseTitle(strings.Join(title, " "))
+	todo.Title = parsed.Title
+	todo.Project = parsed.Project
+	for _, tag := range parsed.Tags {
+		todo.AddTag(tag)
+	}
+
+	todo.CreatedAt = preciseDate(createdOn, created)
+	if todo.IsDone {
+		todo.CompletedAt = preciseDate(completedOn, completed)
+	}
+	if todo.UpdatedAt.IsZero() {
+		todo.UpdatedAt = todo.CreatedAt
+		if todo.CompletedAt.After(todo.UpdatedAt) {
+			todo.UpdatedAt = todo.CompletedAt
+		}
+	}
+	return todo, nil
+}
+
+// escapeTitleWord escapes a word of a title that starts with the key of an extension, such as `rec:room`, so
+// that it is not read as one. The colon or percent sign following the key is encoded as %3A or %25.
+func escapeTitleWord(word string) string {
+	i := strings.IndexAny(word, ":%")
+	if i < 0 || !todoTxtKeys[word[:i]] {
+		return word
+	}
+	if word[i] == ':' {
+		return word[:i] + "%3A" + word[i+1:]
+	}
+	return word[:i] + "%25" + word[i+1:]
+}
+
+// unescapeTitleWord reverses escapeTitleWord.
+func unescapeTitleWord(word string) string {
+	i := strings.IndexByte(word, '%')
+	if i < 0 || !todoTxtKeys[word[:i]] || len(word) < i+3 {
+		return word
+	}
+	switch word[i+1 : i+3] {
+	case "3A":
+		return word[:i] + ":" + word[i+3:]
+	case "25":
+		return word[:i] + "%" + word[i+3:]
+	}
+	return word
+}
+
+// preciseDate returns the timestamp if it falls on the date or there is no date, and else the date.
+func preciseDate(date, timestamp time.Time) time.Time {
+	if date.IsZero() || (!timestamp.IsZero() && startOfDay(timestamp).Equal(date)) {
+		return timestamp
+	}
+	return date
+}
@@ -0,0 +1,137 @@
+package todo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTodoListTodoTxtStore(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 30, 0, 0, time.Local)
+	done := TodoItem{
+		ID:           "3fa1c2d4e5b6a7f8",
+		Num:          2,
+		ParentID:     "9c0d1e2f3a4b5c6d",
+		Title:        "Call mom about the 10:30 train",
+		Description:  "Ask about Sunday\nand bring cake",
+		IsDone:       true,
+		Priority:     "B",
+		DueAt:        time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local),
+		Recurrence:   "FREQ=WEEKLY;BYDAY=MO,TH",
+		Project:      "family",
+		Tags:         []string{"phone", "home"},
+		Dependencies: []string{"a1", "b2"},
+		CreatedAt:    created,
+		UpdatedAt:    created.Add(48 * time.Hour),
+		CompletedAt:  created.Add(24 * time.Hour),
+	}
+	open := TodoItem{
+		ID:        "9c0d1e2f3a4b5c6d",
+		Num:       1,
+		Title:     "Plan trip",
+		Priority:  "A",
+		DueAt:     time.Date(2026, 10, 21, 17, 0, 0, 0, time.Local),
+		CreatedAt: created,
+		UpdatedAt: created,
+		DeletedAt: created.Add(time.Hour),
+	}
+
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	store := NewStore(path)
+	if err := store.Save([]TodoItem{done, open}); err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "x 2026-10-02 2026-10-01 Call mom about the 10:30 train +family @phone @home due:2026-10-20 ") {
+		t.Errorf("Expected a todo.txt line, got %s", data)
+	}
+
+	todos, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	for i, want := range []TodoItem{done, open} {
+		wantJSON, _ := json.Marshal(want)
+		gotJSON, _ := json.Marshal(todos[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("Expected %s, got %s", wantJSON, gotJSON)
+		}
+	}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.Local)
+	}
+
+	todo, err := ParseTodoTxt("x 2026-10-18 2026-10-01 Call mom +family +phone @errands t:2026-10-15 due:2026-10-20")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if !todo.IsDone || !todo.CompletedAt.Equal(day(10, 18)) || !todo.CreatedAt.Equal(day(10, 1)) || !todo.UpdatedAt.Equal(day(10, 18)) {
+		t.Errorf("Expected done on 2026-10-18 and created on 2026-10-01, got %+v", todo)
+	}
+	if todo.Title != "Call mom +phone t:2026-10-15" || todo.Project != "family" || !todo.HasTag("errands") {
+		t.Errorf("Expected other tokens in the title, got %+v", todo)
+	}
+	if !todo.DueAt.Equal(day(10, 20)) || todo.ID != "" {
+		t.Errorf("Expected due on 2026-10-20 without ID, got %+v", todo)
+	}
+
+	// A date changed by another app wins over the timestamp.
+	todo, err = ParseTodoTxt("(C) 2026-10-03 Pay rent created:2026-10-01T09:30:00Z")
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if todo.Priority != "C" || !todo.CreatedAt.Equal(day(10, 3)) {
+		t.Errorf("Expected priority C created on 2026-10-03, got %+v", todo)
+	}
+
+	for _, line := range []string{"Pay rent due:soon", "Pay rent num:one", "Pay rent rec:yearly", "x Pay rent pri:AA"} {
+		if _, err := ParseTodoTxt(line); err == nil {
+			t.Errorf("Expected error for %s, got nil", line)
+		}
+	}
+}
+
+func TestTodoTxtTitleExtensions(t *testing.T) {
+	// Words of a title starting with the key of an extension are kept in the title.
+	for _, title := range []string{"Book rec:room", "Check due:soon", "Fix id:abc bug", "Read due%3Asoon", "Pay 50% num:", "Note: desc:a:b"} {
+		t.Run(title, func(t *testing.T) {
+			line := FormatTodoTxt(TodoItem{ID: "a1", Title: title})
+			todo, err := ParseTodoTxt(line)
+			if err != nil {
+				t.Fatalf("Expected nil, got %s", err)
+			}
+			if todo.Title != title || todo.ID != "a1" {
+				t.Errorf("Expected %s with ID a1, got %+v from %s", title, todo, line)
+			}
+			if todo.HasDue() || todo.Recurrence != RecurrenceNone || todo.Description != "" {
+				t.Errorf("Expected no extensions, got %+v from %s", todo, line)
+			}
+		})
+	}
+}
+
+func TestTodoListTodoTxtStoreIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(path, []byte("Buy milk\n\nBuy milk\n(A) Call mom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines without an ID get the same IDs every time they are loaded.
+	first, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	second, err := NewTodoList(NewStore(path))
+	if err != nil {
+		t.Fatalf("Expected nil, got %s", err)
+	}
+	if len(first.Todos) != 3 || ids(first.Todos) != ids(second.Todos) {
+		t.Errorf("Expected the same 3 IDs, got %s and %s", ids(first.Todos), ids(second.Todos))
+	}
+}